@@ -3,13 +3,17 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"syscall"
 	"time"
 
 	"go-demo/config"
 	"go-demo/config/di"
 	"go-demo/internal/middleware"
 	"go-demo/internal/router"
+	"go-demo/internal/validator"
 	"go-demo/pkg/ginx"
+	"go-demo/pkg/metricx"
 
 	"github.com/fvbock/endless"
 	"github.com/gin-gonic/gin"
@@ -21,26 +25,58 @@ func main() {
 	if lo.Contains([]string{"prod", "stage"}, config.RuntimeEnv()) {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	r := gin.Default()
+	r := gin.New()
+
+	// 注册自定义参数类型
+	validator.RegisterTypes()
+
+	// 错误输出格式
+	ginx.SetErrorFormat(ginx.ErrorFormat(config.GetString("error_format")))
+	ginx.SetProblemTypeBase(config.GetString("problem_type_base"))
+
+	// 限流, 与健康检查及指标共用限额
+	qpsLimit := middleware.QPSLimit(config.GetInt("qps_limit"))
+
+	// 健康检查与指标, 在全局中间件之前注册, 不记录访问日志, 不受超时影响, 但受限流保护
+	router.Health(r, middleware.Recovery(), qpsLimit)
+	if config.GetBool("metrics") {
+		router.Metrics(r, middleware.Recovery(), qpsLimit)
+	}
+	metricx.Serve(config.GetString("api_metrics_addr"), nil)
 
 	r.Use(
-		middleware.Recovery(),                           // panic 处理
-		middleware.CORS(),                               // 跨域处理
-		middleware.QPSLimit(config.GetInt("qps_limit")), // 限流
+		middleware.RequestID(), // 请求 ID
+		middleware.AccessLog(), // 访问日志
+		middleware.Metrics(),   // 指标
+		middleware.CORS(),      // 跨域处理
+		qpsLimit,               // 限流
 		middleware.Timeout(time.Duration(config.GetInt("timeout"))*time.Second), // 超时控制
+		middleware.Recovery(), // panic 处理, 在超时控制的 Goroutine 中执行, 已超时的请求也能上报
 	)
 
 	// 加载路由 DEMO
 	router.Account(r)
 
+	// 接口文档
+	if config.GetBool("openapi") {
+		router.OpenAPI(r)
+	}
+
 	// 未知路由处理
 	r.NoRoute(func(c *gin.Context) {
-		ginx.Error(c, 404, "ResourceNotFound", "您请求的资源不存在")
+		ginx.Fail(c, ginx.ErrNotFound)
 	})
 
 	// Run Gin
 	addr := fmt.Sprintf(":%d", config.GetInt("server_port"))
-	if err := endless.ListenAndServe(addr, r); err != nil {
+	srv := endless.NewServer(addr, r)
+	for _, sig := range []os.Signal{syscall.SIGINT, syscall.SIGTERM} { // 停止前就绪检查失败, 等待负载均衡摘除
+		srv.SignalHooks[endless.PRE_SIGNAL][sig] = append(srv.SignalHooks[endless.PRE_SIGNAL][sig], func() {
+			di.Health().Stop()
+			time.Sleep(time.Duration(config.GetInt("shutdown_delay")) * time.Second)
+		})
+	}
+	if err := srv.ListenAndServe(); err != nil {
 		di.Logger().Error(err.Error())
 		return
 	}
@@ -24,6 +24,11 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:   "openapi",
+				Usage:  "生成 OpenAPI 文档, 参数为输出文件路径, 不传则输出到标准输出",
+				Action: action.OpenAPI.Generate,
+			},
 		},
 	}
 
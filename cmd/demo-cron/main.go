@@ -2,17 +2,38 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"go-demo/config"
 	"go-demo/config/di"
 	"go-demo/internal/cron"
+	"go-demo/pkg/metricx"
+	"go-demo/pkg/panicx"
 
 	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
 )
 
 func main() {
+	// 指标与健康检查
+	opsMux := http.NewServeMux()
+	di.Health().Register(opsMux)
+	metricx.Serve(config.GetString("cron_metrics_addr"), opsMux)
+
 	// create a scheduler
-	s, err := gocron.NewScheduler()
+	s, err := gocron.NewScheduler(gocron.WithGlobalJobOptions(gocron.WithEventListeners(
+		gocron.AfterJobRunsWithPanic(func(jobID uuid.UUID, jobName string, recoverData any) { // panic 上报
+			di.Panic().Capture(context.Background(), recoverData, panicx.Event{
+				Origin: panicx.OriginCron,
+				Extra:  map[string]string{"job": jobName, "job_id": jobID.String()},
+			})
+		}),
+	)))
 	if err != nil {
 		di.Logger().Error(err.Error())
 		return
@@ -28,6 +49,7 @@ func main() {
 	if _, err := s.NewJob(
 		gocron.DurationJob(10*time.Second),
 		gocron.NewTask(cron.User.DeleteUsers, 10),
+		gocron.WithName("User.DeleteUsers"),
 	); err != nil {
 		di.Logger().Error(err.Error())
 	}
@@ -36,5 +58,8 @@ func main() {
 	s.Start()
 
 	// block until you are ready to shut down
-	select {}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+	di.Health().Stop()
 }
@@ -3,22 +3,35 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"go-demo/config"
 	"go-demo/config/di"
 	"go-demo/internal/task"
+	"go-demo/pkg/logx"
+	"go-demo/pkg/metricx"
+	"go-demo/pkg/panicx"
+	"go-demo/pkg/queuex"
 
 	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
 )
 
+// loggingMiddleware 任务日志, 恢复发送任务的请求 ID 到 ctx
 func loggingMiddleware(h asynq.Handler) asynq.Handler {
 	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		ctx = queuex.Context(ctx, t)
 		start := time.Now()
 		log.Printf("Start processing %q --- %s", t.Type(), t.Payload())
 
 		if err := h.ProcessTask(ctx, t); err != nil {
-			di.Logger().Error(err.Error())
+			logx.L(ctx).Error(err.Error(), zap.String("task", t.Type()))
 			return err
 		}
 
@@ -27,17 +40,52 @@ func loggingMiddleware(h asynq.Handler) asynq.Handler {
 	})
 }
 
+// recoverMiddleware 任务 panic 上报, 转为错误按重试策略处理
+func recoverMiddleware(h asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				di.Panic().Capture(ctx, r, panicx.Event{
+					Origin: panicx.OriginQueue,
+					Extra:  map[string]string{"task": t.Type()},
+				})
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		return h.ProcessTask(ctx, t)
+	})
+}
+
 func main() {
 	// mux maps a type to a handler
 	mux := asynq.NewServeMux()
-	mux.Use(loggingMiddleware)
+	mux.Use(loggingMiddleware, metricx.TaskMiddleware, recoverMiddleware)
+
+	// 指标与健康检查
+	opsMux := http.NewServeMux()
+	di.Health().Register(opsMux)
+	metricx.Serve(config.GetString("queue_metrics_addr"), opsMux)
 
 	// register handler DEMO
 	mux.HandleFunc("User:AddUser", task.User.AddUser)
 
 	// run queue server
-	if err := di.QueueServer().Run(mux); err != nil {
+	if err := di.QueueServer().Start(mux); err != nil {
 		di.Logger().Error(err.Error())
 		return
 	}
+
+	// 信号处理同 asynq Server.Run(): SIGTSTP 停止拉取新任务, SIGINT/SIGTERM 就绪检查失败后等待处理中的任务完成并退出
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGTSTP)
+	for sig := range sigCh {
+		di.Health().Stop()
+		if sig == syscall.SIGTSTP {
+			di.QueueServer().Stop()
+			continue
+		}
+		break
+	}
+	di.QueueServer().Shutdown()
 }
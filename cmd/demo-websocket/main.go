@@ -6,15 +6,21 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"go-demo/config"
 	"go-demo/config/di"
 	"go-demo/internal/consts"
 	"go-demo/internal/service"
 	"go-demo/internal/types"
 	"go-demo/internal/ws"
 	"go-demo/pkg/gox"
+	"go-demo/pkg/i18nx"
+	"go-demo/pkg/metricx"
 
 	"github.com/goccy/go-json"
 	"github.com/gorilla/websocket"
@@ -30,6 +36,11 @@ var (
 func socketHandler(w http.ResponseWriter, r *http.Request) {
 	// 将 ws 连接信息和 user_id 记录到 WSClient 对象
 	client := &types.WSClient{Conn: nil, IsClosed: true}
+	// 客户端语言, URL 参数 lang 优先, 其次为请求头 Accept-Language
+	client.Locale = i18nx.Match(r.Header.Get("Accept-Language"))
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		client.Locale = i18nx.Match(lang)
+	}
 
 	// Upgrade our raw HTTP connection to a websocket based one
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -39,6 +50,7 @@ func socketHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	client.Conn = conn
 	client.IsClosed = false
+	defer metricx.WSConnect()()
 	// Close
 	defer service.WS.Close(client)
 
@@ -46,32 +58,20 @@ func socketHandler(w http.ResponseWriter, r *http.Request) {
 	clientID := r.URL.Query().Get("client_id") // url_base64(userID:md5(jwtToken))
 	clientIDDecoded, err := base64.RawURLEncoding.DecodeString(clientID)
 	if err != nil {
-		_ = service.WS.Send(client, "ClientError", map[string]any{
-			"code":    "UserUnauthorized",
-			"message": "您未登录或登录已过期, 请重新登录",
-		})
+		_ = service.WS.SendError(client, "ClientError", "UserUnauthorized", "您未登录或登录已过期, 请重新登录")
 		return
 	}
 	userJWT := strings.Split(string(clientIDDecoded), ":")
 	if len(userJWT) != 2 {
-		_ = service.WS.Send(client, "ClientError", map[string]any{
-			"code":    "UserUnauthorized",
-			"message": "您未登录或登录已过期, 请重新登录",
-		})
+		_ = service.WS.SendError(client, "ClientError", "UserUnauthorized", "您未登录或登录已过期, 请重新登录")
 		return
 	}
 	key := fmt.Sprintf(consts.JWTLogin, consts.UserJWT, userJWT[0], userJWT[1])
-	if n, err := di.JWTRedis().Exists(context.Background(), key).Result(); err != nil {
-		_ = service.WS.Send(client, "ClientError", map[string]any{
-			"code":    "InternalError",
-			"message": "服务异常, 请稍后重试",
-		})
+	if n, err := di.JWTRedis().Exists(r.Context(), key).Result(); err != nil {
+		_ = service.WS.SendError(client, "ClientError", "InternalError", "服务异常, 请稍后重试")
 		return
 	} else if n == 0 {
-		_ = service.WS.Send(client, "ClientError", map[string]any{
-			"code":    "UserUnauthorized",
-			"message": "您未登录或登录已过期, 请重新登录",
-		})
+		_ = service.WS.SendError(client, "ClientError", "UserUnauthorized", "您未登录或登录已过期, 请重新登录")
 		return
 	}
 	client.UserID = cast.ToInt64(userJWT[0])
@@ -103,14 +103,11 @@ func socketHandler(w http.ResponseWriter, r *http.Request) {
 	// 这里通过 redis 订阅来实现, 服务端监听名为 WSMessageChannel 的 redis 频道
 	// 向频道发送消息的格式为 json 字符串 `{"user_id": int, "type": string, data: {}}`
 	// user_id 为 0 表示向所有用户推送消息, 否则为向指定用户推送消息
-	pubsub := di.StorageRedis().Subscribe(context.Background(), "WSMessageChannel") // 订阅一个或多个频道
+	pubsub := di.StorageRedis().Subscribe(r.Context(), "WSMessageChannel") // 订阅一个或多个频道
 	// 检查订阅是否成功
-	if _, err := pubsub.Receive(context.Background()); err != nil {
+	if _, err := pubsub.Receive(r.Context()); err != nil {
 		di.Logger().Error(err.Error())
-		_ = service.WS.Send(client, "InternalError", map[string]any{ // 订阅失败
-			"code":    "InternalError",
-			"message": "服务异常, 请稍后重试",
-		})
+		_ = service.WS.SendError(client, "InternalError", "InternalError", "服务异常, 请稍后重试") // 订阅失败
 		return
 	}
 	// 创建一个通道来接收订阅的消息
@@ -151,29 +148,40 @@ func socketHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		msg := types.WSMsg{}
 		if err := json.Unmarshal(message, &msg); err != nil {
-			_ = service.WS.Send(client, "ClientError", map[string]any{
-				"code":    "MessageError",
-				"message": "消息格式不正确",
-			})
+			_ = service.WS.SendError(client, "ClientError", "MessageError", "消息格式不正确")
 			continue
 		}
 
 		// 业务路由
 		switch msg.Type {
 		case "MicroChat:SendMessage": // DEMO
+			metricx.WSMessage("in", msg.Type)
 			ws.MicroChat.SendMessage(client, msg.Data)
 		default: // 未知路由
-			_ = service.WS.Send(client, "ClientError", map[string]any{
-				"code":    "TypeError",
-				"message": "未知消息类型",
-			})
+			metricx.WSMessage("in", "unknown")
+			_ = service.WS.SendError(client, "ClientError", "TypeError", "未知消息类型")
 		}
 	}
 }
 
 func main() {
 	http.HandleFunc("/websocket", socketHandler)
-	if err := http.ListenAndServe(":9090", nil); err != nil {
+	http.Handle("/metrics", metricx.Handler())
+	di.Health().Register(http.DefaultServeMux)
+
+	srv := &http.Server{Addr: ":9090"}
+	gox.SafeGo(func() {
+		// 停止: 就绪检查失败, 等待负载均衡摘除后不再接受新连接
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		<-sigCh
+		di.Health().Stop()
+		time.Sleep(time.Duration(config.GetInt("shutdown_delay")) * time.Second)
+		if err := srv.Shutdown(context.Background()); err != nil {
+			di.Logger().Error(err.Error())
+		}
+	})
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		di.Logger().Error(err.Error())
 		return
 	}
@@ -65,6 +65,14 @@ func GetString(key string) string {
 	return value
 }
 
+func GetFloat64(key string) float64 {
+	value, err := cast.ToFloat64E(get(key))
+	if err != nil {
+		zap.L().Error(err.Error())
+	}
+	return value
+}
+
 func GetBool(key string) bool {
 	value, err := cast.ToBoolE(get(key))
 	if err != nil {
@@ -96,3 +104,11 @@ func GetStringMapString(key string) map[string]string {
 	}
 	return value
 }
+
+func GetStringMap(key string) map[string]any {
+	value, err := cast.ToStringMapE(get(key))
+	if err != nil {
+		zap.L().Error(err.Error())
+	}
+	return value
+}
@@ -18,15 +18,78 @@ func init() {
 		// ERROR 日志级别
 		"error_log_level": "Debug", // Debug, Info, Warn, Error
 
+		// panic 去重窗口, 秒, 窗口内同一 panic 只上报一次
+		"panic_dedup_window": 60,
+		// panic 事件本地文件路径, 每行一个 JSON, 空表示不记录
+		"panic_log": "",
+		// panic 告警 webhook, 空表示不告警
+		"panic_webhook": "",
+
+		// 访问日志路径, 空表示输出到控制台
+		"access_log": "",
+		// 访问日志记录请求/响应内容的采样率, 0~1, 0 表示不记录
+		"access_log_body_sample": 0,
+		// 访问日志请求/响应内容最大记录字节数
+		"access_log_body_limit": 4096,
+		// 访问日志脱敏字段, 匹配 JSON/表单/Query 字段名与请求头, 不区分大小写
+		"access_log_redact": []string{"password", "token", "secret", "authorization", "cookie", "x-api-key"},
+
 		// 公共 Goroutine 池大小
 		"worker_pool": 409600,
 
-		// 限流 QPS
+		// 单实例限流 QPS, 本地令牌桶, 限额按实例计算, 0 表示不限流
 		"qps_limit": 40000,
 
+		// 跨域策略, routes 按路径前缀覆盖, 未配置的项沿用默认策略
+		"cors": map[string]any{
+			"allow_origins":     []string{"*"}, // * 允许全部, 支持通配符 https://*.example.com, ~ 开头为正则
+			"allow_methods":     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			"allow_headers":     []string{"Authorization", "Content-Type", "Accept", "Accept-Language", "X-Request-ID", "X-API-Key", "Idempotency-Key", "If-None-Match"},
+			"expose_headers":    []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Idempotent-Replayed", "Content-Disposition", "ETag", "X-Cache"},
+			"allow_credentials": false,
+			"max_age":           1728000,
+			"routes":            map[string]any{
+				// "/account/v1/login": map[string]any{"allow_origins": []string{"https://*.example.com"}, "allow_credentials": true},
+			},
+		},
+
+		// 幂等请求体最大字节数, 超出时输出 413
+		"idempotency_body_limit": 1 << 20,
+
+		// 响应缓存本地缓存条数, 0 表示不使用本地缓存
+		"response_cache_local_size": 10000,
+		// 响应缓存本地缓存时长, 秒
+		"response_cache_local_ttl": 10,
+		// 响应缓存标签版本本地缓存时长, 秒, 其他实例失效后最多延迟此时长生效, 0 表示每次从 Redis 读取
+		"response_cache_tag_ttl": 1,
+
+		// 停止时就绪检查失败后等待秒数, 等待负载均衡摘除后再停止服务
+		"shutdown_delay": 5,
+		// 就绪检查是否输出依赖错误详情, 可能包含内部地址等信息, 仅在检查接口不对外暴露时开启
+		"health_detail": false,
+
 		// 超时控制, 秒
 		"timeout": 30,
 
+		// 外部消息目录, 目录下 <locale>.json 覆盖内置消息目录 config/i18n/, 空表示不使用
+		"i18n_dir": "",
+
+		// 错误输出格式, default: {"code","message"}, problem: RFC 7807 application/problem+json, negotiate: 按请求头 Accept 协商
+		"error_format": "default",
+		// problem type URI 前缀, 拼接错误码, 空表示 about:blank
+		"problem_type_base": "",
+
+		// 是否开放接口文档 /openapi.json
+		"openapi": true,
+
+		// 是否在 API 端口开放指标 /metrics, 会对外暴露路由与运行时信息, WebSocket 指标固定在其端口的 /metrics
+		"metrics": true,
+		// API 指标内网服务地址, 空表示不启动
+		"api_metrics_addr": "",
+		// 消息队列/计划任务指标与健康检查服务地址, 空表示不启动
+		"queue_metrics_addr": ":9091",
+		"cron_metrics_addr":  ":9092",
+
 		/************ 配置项 END ******************/
 	} {
 		configure[env][k] = v
@@ -2,6 +2,9 @@ package di
 
 import (
 	"sync"
+	"time"
+
+	"go-demo/config"
 
 	"github.com/go-redis/cache/v9"
 )
@@ -9,6 +12,12 @@ import (
 var (
 	goRedisCache     *cache.Cache
 	goRedisCacheOnce sync.Once
+
+	responseCache     *cache.Cache
+	responseCacheOnce sync.Once
+
+	responseCacheTags     cache.LocalCache
+	responseCacheTagsOnce sync.Once
 )
 
 // Cache go-redis cache
@@ -21,3 +30,30 @@ func Cache() *cache.Cache {
 
 	return goRedisCache
 }
+
+// ResponseCache 响应缓存, Redis + 本地两级缓存
+func ResponseCache() *cache.Cache {
+	responseCacheOnce.Do(func() {
+		options := &cache.Options{
+			Redis: CacheRedis(),
+		}
+		if size := config.GetInt("response_cache_local_size"); size > 0 {
+			options.LocalCache = cache.NewTinyLFU(size, time.Duration(config.GetInt("response_cache_local_ttl"))*time.Second)
+		}
+		responseCache = cache.New(options)
+	})
+
+	return responseCache
+}
+
+// ResponseCacheTags 响应缓存标签版本本地缓存, 避免每个请求都访问 Redis, nil 表示不使用
+func ResponseCacheTags() cache.LocalCache {
+	responseCacheTagsOnce.Do(func() {
+		size, ttl := config.GetInt("response_cache_local_size"), config.GetInt("response_cache_tag_ttl")
+		if size > 0 && ttl > 0 {
+			responseCacheTags = cache.NewTinyLFU(size, time.Duration(ttl)*time.Second)
+		}
+	})
+
+	return responseCacheTags
+}
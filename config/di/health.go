@@ -0,0 +1,55 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-demo/config"
+	"go-demo/pkg/healthx"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	health     *healthx.Checker
+	healthOnce sync.Once
+)
+
+// Health 健康检查
+//
+//	检查数据库, 各 redis 与消息队列连接, 单个依赖检查超时时间为2秒, 检查结果缓存1秒. 配置项 health_detail 开启时输出依赖错误详情.
+func Health() *healthx.Checker {
+	healthOnce.Do(func() {
+		health = healthx.New(2*time.Second).
+			CacheFor(time.Second).
+			Detail(config.GetBool("health_detail")).
+			Add("mysql_demo", func(ctx context.Context) error {
+				db := DemoDB()
+				if db == nil {
+					return errors.New("数据库连接失败")
+				}
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			}).
+			Add("redis_cache", redisCheck(CacheRedis)).
+			Add("redis_storage", redisCheck(StorageRedis)).
+			Add("redis_jwt", redisCheck(JWTRedis)).
+			Add("queue", func(context.Context) error {
+				return QueueClient().Ping()
+			})
+	})
+
+	return health
+}
+
+// redisCheck redis 连接检查
+func redisCheck(client func() *redis.Client) healthx.CheckFunc {
+	return func(ctx context.Context) error {
+		return client().Ping(ctx).Err()
+	}
+}
@@ -0,0 +1,21 @@
+package di
+
+import (
+	"sync"
+
+	"go-demo/pkg/limitx"
+)
+
+var (
+	limiter     *limitx.Limiter
+	limiterOnce sync.Once
+)
+
+// Limiter 分布式限流器
+func Limiter() *limitx.Limiter {
+	limiterOnce.Do(func() {
+		limiter = limitx.NewLimiter(CacheRedis())
+	})
+
+	return limiter
+}
@@ -3,6 +3,7 @@ package di
 
 import (
 	"os"
+	"sync"
 
 	"go-demo/config"
 
@@ -10,9 +11,10 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var zapLogger *zap.Logger
+var zapLogger = newLogger() // 包级变量先于所有 init() 初始化, 其他 init() 中可以直接使用日志
 
-func init() { // 日志服务最为基础, 日志初始化失败, 程序不允许启动
+// newLogger 创建日志. 日志服务最为基础, 日志初始化失败, 程序不允许启动
+func newLogger() *zap.Logger {
 	// 创建输出位置
 	syncers := make([]zapcore.WriteSyncer, 0) // NewMultiWriteSyncer() 可以添加多个 syncer, 逗号分隔
 	errorLog := config.GetString("error_log")
@@ -46,12 +48,40 @@ func init() { // 日志服务最为基础, 日志初始化失败, 程序不允
 	}
 	zapCore := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(syncers...), logLevel) // 允许记录所有级别日志
 	// 创建 Logger
-	zapLogger = zap.New(zapCore, zap.AddStacktrace(zapcore.ErrorLevel)) // 错误日志记录栈信息
+	logger := zap.New(zapCore, zap.AddStacktrace(zapcore.ErrorLevel)) // 错误日志记录栈信息
 	// 替换 zap 包中全局的 zapLogger 实例, 后续在其他包中只需使用 zap.L() 调用即可
-	zap.ReplaceGlobals(zapLogger)
+	zap.ReplaceGlobals(logger)
+
+	return logger
 }
 
 // Logger 日志
 func Logger() *zap.Logger {
 	return zapLogger
 }
+
+var (
+	accessLogger     *zap.Logger
+	accessLoggerOnce sync.Once
+)
+
+// AccessLogger 访问日志
+//
+//	独立于错误日志, 输出位置由 access_log 配置, 不受 error_log_level 影响.
+func AccessLogger() *zap.Logger {
+	accessLoggerOnce.Do(func() {
+		syncer := zapcore.AddSync(os.Stdout)
+		if accessLog := config.GetString("access_log"); accessLog != "" { // 输出到文件
+			logFile, err := os.OpenFile(accessLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o664)
+			if err != nil {
+				panic(err)
+			}
+			syncer = zapcore.AddSync(logFile)
+		}
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05")
+		accessLogger = zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), syncer, zapcore.InfoLevel))
+	})
+
+	return accessLogger
+}
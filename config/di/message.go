@@ -0,0 +1,21 @@
+// Package di 服务注入
+package di
+
+import (
+	"go-demo/config"
+	"go-demo/pkg/i18nx"
+
+	"go.uber.org/zap"
+)
+
+func init() { // 消息目录初始化时加载, 加载失败仅记录日志, 使用默认语言消息
+	if err := i18nx.LoadFS(config.I18nFS, "i18n"); err != nil {
+		Logger().Error(err.Error())
+	}
+	// 外部消息目录, 与内置消息目录分别加载, 同 key 覆盖内置消息
+	if dir := config.GetString("i18n_dir"); dir != "" {
+		if err := i18nx.LoadDir(dir); err != nil {
+			Logger().Error(err.Error(), zap.String("i18n_dir", dir))
+		}
+	}
+}
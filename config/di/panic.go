@@ -0,0 +1,37 @@
+package di
+
+import (
+	"sync"
+	"time"
+
+	"go-demo/config"
+	"go-demo/pkg/panicx"
+)
+
+var (
+	panicReporter     *panicx.Reporter
+	panicReporterOnce sync.Once
+)
+
+func init() { // 设为 panicx 默认上报器, gox.SafeGo() 等 pkg 内的 recover 同样使用配置的上报目标
+	Panic()
+}
+
+// Panic panic 上报器
+//
+//	输出到错误日志, 按配置 panic_log 记录到本地文件, panic_webhook 发送告警.
+func Panic() *panicx.Reporter {
+	panicReporterOnce.Do(func() {
+		sinks := []panicx.Sink{panicx.ZapSink(Logger())}
+		if panicLog := config.GetString("panic_log"); panicLog != "" {
+			sinks = append(sinks, panicx.FileSink(panicLog))
+		}
+		if webhook := config.GetString("panic_webhook"); webhook != "" {
+			sinks = append(sinks, panicx.WebhookSink(webhook, 5*time.Second))
+		}
+		panicReporter = panicx.New(time.Duration(config.GetInt("panic_dedup_window"))*time.Second, sinks...)
+		panicx.SetDefault(panicReporter)
+	})
+
+	return panicReporter
+}
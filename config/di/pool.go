@@ -2,13 +2,14 @@
 package di
 
 import (
-	"fmt"
+	"context"
 	"sync"
 
 	"go-demo/config"
+	"go-demo/pkg/metricx"
+	"go-demo/pkg/panicx"
 
 	"github.com/alitto/pond"
-	"go.uber.org/zap"
 )
 
 var (
@@ -19,9 +20,8 @@ var (
 // Pool 公共 Goroutine 池
 func Pool() *pond.WorkerPool {
 	wpOnce.Do(func() {
-		workerPool = pond.New(config.GetInt("worker_pool"), 0, pond.PanicHandler(func(a any) {
-			zap.L().Error(fmt.Sprint(a))
-		}))
+		workerPool = pond.New(config.GetInt("worker_pool"), 0, pond.PanicHandler(poolPanicHandler("default")))
+		metricx.RegisterPool("default", workerPool)
 	})
 
 	return workerPool
@@ -31,7 +31,12 @@ func Pool() *pond.WorkerPool {
 //
 //	一次请求提交大量数据, 使用独享 Goroutine 池起限流作用.
 func PoolSeparate(maxWorkers int) *pond.WorkerPool {
-	return pond.New(maxWorkers, 0, pond.PanicHandler(func(a any) {
-		zap.L().Error(fmt.Sprint(a))
-	}))
+	return pond.New(maxWorkers, 0, pond.PanicHandler(poolPanicHandler("separate")))
+}
+
+// poolPanicHandler Goroutine 池任务 panic 上报, 在 panic 所在 Goroutine 中调用, 可以记录栈信息
+func poolPanicHandler(pool string) func(any) {
+	return func(a any) {
+		Panic().Capture(context.Background(), a, panicx.Event{Origin: panicx.OriginPool, Extra: map[string]string{"pool": pool}})
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"go-demo/config"
+	"go-demo/pkg/metricx"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -29,6 +30,7 @@ func CacheRedis() *redis.Client {
 			Password: config.GetString("redis_auth"),
 			DB:       config.GetInt("redis_index_cache"),
 		})
+		cacheRedis.AddHook(metricx.RedisHook("cache"))
 	})
 
 	return cacheRedis
@@ -53,6 +55,7 @@ func StorageRedis() *redis.Client {
 			Password: config.GetString("redis_auth"),
 			DB:       config.GetInt("redis_index_storage"),
 		})
+		storageRedis.AddHook(metricx.RedisHook("storage"))
 	})
 
 	return storageRedis
@@ -75,6 +78,7 @@ func JWTRedis() *redis.Client {
 			Password: config.GetString("redis_auth"),
 			DB:       config.GetInt("redis_index_jwt"),
 		})
+		jwtRedis.AddHook(metricx.RedisHook("jwt"))
 	})
 
 	return jwtRedis
@@ -0,0 +1,11 @@
+// Package config 配置实现
+package config
+
+import "embed"
+
+// I18nFS 内置消息目录
+//
+//	i18n/<locale>.json, 内容格式 {"错误码或参数名称": "消息"}, 默认语言(中文)消息写在代码中, 无需消息目录.
+//
+//go:embed i18n/*.json
+var I18nFS embed.FS
@@ -22,6 +22,12 @@ func init() {
 		// 日志
 		"error_log_level": "Error",
 
+		// 不开放接口文档
+		"openapi": false,
+		// 指标不在 API 端口对外开放, 改由内网地址提供
+		"metrics":          false,
+		"api_metrics_addr": ":9090",
+
 		/************ 配置项 END ****************/
 	} {
 		configure[env][k] = v
@@ -0,0 +1,43 @@
+-- 角色与权限
+
+CREATE TABLE `t_roles` (
+  `role_id` bigint NOT NULL AUTO_INCREMENT,
+  `role_name` varchar(50) NOT NULL DEFAULT '' COMMENT '角色标识, 比如 admin',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`role_id`),
+  UNIQUE KEY `role_name` (`role_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色表';
+
+CREATE TABLE `t_permissions` (
+  `permission_id` bigint NOT NULL AUTO_INCREMENT,
+  `permission` varchar(100) NOT NULL DEFAULT '' COMMENT '权限标识, 比如 user:update',
+  `description` varchar(255) NOT NULL DEFAULT '' COMMENT '描述',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`permission_id`),
+  UNIQUE KEY `permission` (`permission`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限表';
+
+CREATE TABLE `t_role_permissions` (
+  `role_id` bigint NOT NULL,
+  `permission_id` bigint NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`role_id`, `permission_id`),
+  KEY `permission_id` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色权限表';
+
+CREATE TABLE `t_user_roles` (
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '登录用户类型, user-用户, admin-管理员',
+  `user_id` bigint NOT NULL,
+  `role_id` bigint NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`user_type`, `user_id`, `role_id`),
+  KEY `role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户角色表';
+
+-- 超级管理员角色 * 拥有全部权限
+INSERT INTO `t_roles` (`role_name`, `description`) VALUES ('admin', '超级管理员');
+INSERT INTO `t_permissions` (`permission`, `description`) VALUES ('*', '全部权限'), ('user:create', '新增用户'), ('user:update', '修改用户信息');
+INSERT INTO `t_role_permissions` (`role_id`, `permission_id`) SELECT r.`role_id`, p.`permission_id` FROM `t_roles` r, `t_permissions` p WHERE r.`role_name` = 'admin' AND p.`permission` = '*';
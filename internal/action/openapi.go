@@ -0,0 +1,57 @@
+// Package action 命令行 action
+package action
+
+import (
+	"fmt"
+	"os"
+
+	"go-demo/config"
+	"go-demo/internal/router"
+	"go-demo/internal/validator"
+	"go-demo/pkg/ginx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/urfave/cli/v2"
+)
+
+// 接口文档相关命令行
+type openAPI struct{}
+
+// OpenAPI 这里仅需结构体零值
+var OpenAPI openAPI
+
+// Generate 生成 OpenAPI 3 文档
+//
+//	参数为输出文件路径, 不传则输出到标准输出.
+func (openAPI) Generate(c *cli.Context) error {
+	gin.SetMode(gin.ReleaseMode)
+	// 与 API 入口一致的自定义参数类型与错误输出格式
+	validator.RegisterTypes()
+	ginx.SetErrorFormat(ginx.ErrorFormat(config.GetString("error_format")))
+	ginx.SetProblemTypeBase(config.GetString("problem_type_base"))
+
+	r := gin.New()
+	router.Account(r)
+
+	doc, err := ginx.OpenAPI(r.Routes(), router.APIInfo)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := c.Args().Get(0)
+	if name == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+	if err := os.WriteFile(name, content, 0o644); err != nil {
+		return err
+	}
+	fmt.Println("处理完毕")
+
+	return nil
+}
@@ -6,3 +6,9 @@ const (
 	UserJWT  = "user"  // 用户登录
 	AdminJWT = "admin" // 管理员登录
 )
+
+// 权限标识 <资源>:<操作>, 需在 t_permissions 表中配置并授予角色. 授予 * 拥有全部权限, 授予 user:* 拥有 user 资源全部权限
+const (
+	PermUserCreate = "user:create" // 新增用户
+	PermUserUpdate = "user:update" // 修改用户信息
+)
@@ -0,0 +1,18 @@
+// Package consts 常量定义
+package consts
+
+import "go-demo/pkg/ginx"
+
+// 错误码, 其他语言的消息在 config/i18n/<locale>.json 中以错误码为 key 配置
+var (
+	ErrTooManyRequests        = ginx.RegisterCode(429, "TooManyRequests", "服务繁忙, 请稍后重试")
+	ErrIdempotencyInFlight    = ginx.RegisterCode(409, "IdempotencyInFlight", "请求处理中, 请稍后重试")
+	ErrIdempotencyKeyMismatch = ginx.RegisterCode(422, "IdempotencyKeyMismatch", "幂等键已用于其他请求")
+	ErrRequestTooLarge        = ginx.RegisterCode(413, "RequestTooLarge", "请求内容过大")
+	ErrUserUnauthorized       = ginx.RegisterCode(401, "UserUnauthorized", "您未登录或登录已过期, 请重新登录")
+	ErrPermissionDenied       = ginx.RegisterCode(403, "PermissionDenied", "您没有权限执行此操作")
+	ErrUserInvalid            = ginx.RegisterCode(400, "UserInvalid", "用户名或密码不正确")
+	ErrUserNotFound           = ginx.RegisterCode(404, "UserNotFound", "用户不存在")
+	ErrUserConflict           = ginx.RegisterCode(400, "UserConflict", "用户名已存在")
+	ErrParamRequired          = ginx.RegisterCode(400, "ParamError", "请至少传递一个参数")
+)
@@ -1,12 +1,27 @@
 // Package consts 常量定义
 package consts
 
+import "time"
+
 // 鉴权
 const (
-	JWTLogin = "%s:%v:jwt:%s" // JWT 登录凭证 <userType>:<userID>:jwt:<md5(jwtToken)>
+	JWTLogin    = "%s:%v:jwt:%s"      // JWT 登录凭证 <userType>:<userID>:jwt:<md5(jwtToken)>
+	Permissions = "%s:%v:permissions" // 权限缓存 <userType>:<userID>:permissions
 )
 
 // 安全
 const (
-	SubmitLimit = "submit:limit:%s" // 提交频率限制, submit:limit:<md5(id|ip&&agent+method+path)>
+	RateLimit   = "rate:limit:%s:%s" // 限流, rate:limit:<policy>:<md5(key)>
+	Idempotency = "idempotency:%s"   // 幂等记录, idempotency:<md5(id|ip&&agent+method+route+key)>
+)
+
+// 响应缓存
+const (
+	ResponseCache    = "response:cache:%s"     // 响应缓存, response:cache:<md5(locale+path+query+user+tags)>
+	ResponseCacheTag = "response:cache:tag:%s" // 标签版本, response:cache:tag:<tag>
+
+	CacheTagUser = "user:%v" // 用户缓存标签, user:<userID>
+
+	ResponseCacheMaxTTL = 24 * time.Hour                  // 响应缓存最大时长
+	ResponseCacheTagTTL = ResponseCacheMaxTTL + time.Hour // 标签版本过期时长, 长于响应缓存, 过期后版本重新计数时旧版本的缓存均已过期
 )
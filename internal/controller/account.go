@@ -2,8 +2,10 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"go-demo/config/di"
 	"go-demo/internal/consts"
@@ -11,10 +13,12 @@ import (
 	"go-demo/internal/service"
 	"go-demo/pkg/ginx"
 	"go-demo/pkg/gox"
+	"go-demo/pkg/logx"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-module/carbon/v2"
 	"github.com/spf13/cast"
+	"go.uber.org/zap"
 )
 
 // 用户相关控制器 DEMO 这里定义一个空结构体用于为大量的 controller 方法做分类
@@ -23,9 +27,29 @@ type account struct{}
 // Account 这里仅需结构体零值
 var Account account
 
+// 参数模式, 同时用于接口文档, 见 account_doc.go
+var (
+	getUsersQueries  = []string{`user_name:用户名:string:""`}
+	postUsersBody    = []string{"user_count:数量:+integer:*"}
+	putUsersByIDBody = []string{"user_name:用户名:string{,50}:?", "password:密码:string:?", "is_vip:VIP身份:[0,1]:?"}
+)
+
+// userLoginRequest 登录参数
+type userLoginRequest struct {
+	UserName string `json:"user_name" ginx:"用户名:string:+"`
+	Password string `json:"password" ginx:"密码:string:+"`
+}
+
+// userListItem 用户列表元素
+type userListItem struct {
+	UserID    int64  `json:"user_id"`
+	UserName  string `json:"user_name"`
+	CreatedAt string `json:"created_at"`
+}
+
 func (account) PostUserLogin(c *gin.Context) {
-	jsonBody, err := ginx.GetJSONBody(c, []string{"user_name:用户名:string:+", "password:密码:string:+"})
-	if err != nil {
+	var req userLoginRequest
+	if err := ginx.BindJSON(c, &req); err != nil {
 		return
 	}
 
@@ -35,17 +59,17 @@ func (account) PostUserLogin(c *gin.Context) {
 		UserName string `json:"user_name"`
 		Password string `json:"password"`
 	}{}
-	if err := di.DemoDB().Model(&model.TUsers{}).Where("user_name = ?", jsonBody["user_name"]).Limit(1).Find(&user).Error; err != nil {
-		ginx.InternalError(c, nil)
+	if err := ginx.DB(c, di.DemoDB()).Model(&model.TUsers{}).Where("user_name = ?", req.UserName).Limit(1).Find(&user).Error; err != nil {
+		ginx.Fail(c, err)
 		return
 	}
-	if user.UserID == 0 || !gox.PasswordVerify(jsonBody["password"].(string), user.Password) {
-		ginx.Error(c, 400, "UserInvalid", "用户名或密码不正确")
+	if user.UserID == 0 || !gox.PasswordVerify(req.Password, user.Password) {
+		ginx.Fail(c, consts.ErrUserInvalid)
 		return
 	}
 
 	// JWT 登录
-	token, err := service.Auth.JWTLogin(consts.UserJWT, user.UserID, user.UserName)
+	token, err := service.Auth.JWTLogin(c.Request.Context(), consts.UserJWT, user.UserID, user.UserName)
 	if err != nil {
 		ginx.InternalError(c, nil)
 		return
@@ -57,7 +81,7 @@ func (account) PostUserLogin(c *gin.Context) {
 func (account) DeleteUserLogout(c *gin.Context) {
 	userID := c.GetInt64("userID")
 	token := c.Request.Header.Get("Authorization")[7:]
-	if err := service.Auth.JWTLogout(consts.UserJWT, token, userID); err != nil {
+	if err := service.Auth.JWTLogout(c.Request.Context(), consts.UserJWT, token, userID); err != nil {
 		ginx.InternalError(c, nil)
 		return
 	}
@@ -67,7 +91,7 @@ func (account) DeleteUserLogout(c *gin.Context) {
 
 func (account) GetUsers(c *gin.Context) {
 	// 假设需要分页并可以按名称搜索
-	queries, err := ginx.GetQueries(c, []string{`user_name:用户名:string:""`})
+	queries, err := ginx.GetQueries(c, getUsersQueries)
 	if err != nil {
 		return
 	}
@@ -81,17 +105,25 @@ func (account) GetUsers(c *gin.Context) {
 		bindParams = append(bindParams, "%"+userName+"%")
 	}
 
-	items := make([]struct {
-		UserID    int64  `json:"user_id"`
-		UserName  string `json:"user_name"`
-		CreatedAt string `json:"created_at"`
-	}, 0)
+	items := make([]userListItem, 0)
 	paging, err := ginx.Paginate(c, &items, ginx.PageQuery{
 		DB:         di.DemoDB(),
 		Model:      &model.TUsers{},
 		Where:      strings.Join(where, " AND "),
 		BindParams: bindParams,
 		OrderBy:    "user_id DESC",
+		Sortable:   []string{model.TUsersColumns.UserID, model.TUsersColumns.CreatedAt},
+		Filterable: map[string]string{model.TUsersColumns.UserID: "+integer", model.TUsersColumns.IsVip: "[0,1]", model.TUsersColumns.CreatedAt: "datetime"},
+		Selectable: []string{model.TUsersColumns.UserID, model.TUsersColumns.UserName, model.TUsersColumns.CreatedAt},
+		Count:      ginx.CountCached,
+		CountCache: di.Cache(),
+		CountTTL:   time.Minute,
+		Export: []ginx.ExportColumn{
+			{Key: model.TUsersColumns.UserID, Label: "用户id"},
+			{Key: model.TUsersColumns.UserName, Label: "用户名"},
+			{Key: model.TUsersColumns.CreatedAt, Label: "创建时间"},
+		},
+		ExportName: "users",
 	})
 	if err != nil {
 		return
@@ -107,12 +139,12 @@ func (account) GetUsersByID(c *gin.Context) {
 	}
 
 	user := model.TUsers{}
-	if err := di.DemoDB().Where("user_id = ?", userID).Find(&user).Error; err != nil {
-		ginx.InternalError(c, nil)
+	if err := ginx.DB(c, di.DemoDB()).Where("user_id = ?", userID).Find(&user).Error; err != nil {
+		ginx.Fail(c, err)
 		return
 	}
 	if user.UserID == 0 {
-		ginx.Error(c, 404, "UserNotFound", "用户不存在")
+		ginx.Fail(c, consts.ErrUserNotFound)
 		return
 	}
 
@@ -120,7 +152,7 @@ func (account) GetUsersByID(c *gin.Context) {
 }
 
 func (account) PostUsers(c *gin.Context) {
-	jsonBody, err := ginx.GetJSONBody(c, []string{"user_count:数量:+integer:*"})
+	jsonBody, err := ginx.GetJSONBody(c, postUsersBody)
 	if err != nil {
 		return
 	}
@@ -131,6 +163,7 @@ func (account) PostUsers(c *gin.Context) {
 	}
 
 	// 多线程写 Demo
+	ctx := c.Request.Context() // gin.Context 不要在 Goroutine 中使用
 	ch := make(chan error, userCount)
 	psg := di.PoolSeparate(100).Group()
 	for i := 0; i < userCount; i++ {
@@ -139,7 +172,7 @@ func (account) PostUsers(c *gin.Context) {
 				UserName: fmt.Sprintf("U%d%d", carbon.Now().Timestamp(), gox.RandInt64(1111, 9999)),
 				Password: gox.PasswordHash("111111"),
 			}
-			if err := di.DemoDB().Create(&user).Error; err != nil {
+			if err := di.DemoDB().WithContext(ctx).Create(&user).Error; err != nil {
 				ch <- err
 			}
 		})
@@ -157,24 +190,24 @@ func (account) PutUsersByID(c *gin.Context) {
 		return
 	}
 
-	jsonBody, err := ginx.GetJSONBody(c, []string{"user_name:用户名:string:?", "password:密码:string:?", "is_vip:VIP身份:[0,1]:?"})
+	jsonBody, err := ginx.GetJSONBody(c, putUsersByIDBody)
 	if err != nil {
 		return
 	}
 	if len(jsonBody) == 0 {
-		ginx.Error(c, 400, "ParamError", "请至少传递一个参数")
+		ginx.Fail(c, consts.ErrParamRequired)
 		return
 	}
 
 	user := struct {
 		UserID int64
 	}{}
-	if err := di.DemoDB().Model(&model.TUsers{}).Where("user_id = ?", userID).Find(&user).Error; err != nil {
-		ginx.InternalError(c, nil)
+	if err := ginx.DB(c, di.DemoDB()).Model(&model.TUsers{}).Where("user_id = ?", userID).Find(&user).Error; err != nil {
+		ginx.Fail(c, err)
 		return
 	}
 	if user.UserID == 0 {
-		ginx.Error(c, 404, "UserNotFound", "用户不存在")
+		ginx.Fail(c, consts.ErrUserNotFound)
 		return
 	}
 
@@ -182,12 +215,12 @@ func (account) PutUsersByID(c *gin.Context) {
 		conflictUser := struct {
 			UserID int64
 		}{}
-		if err := di.DemoDB().Model(&model.TUsers{}).Where("user_name = ? AND user_id != ?", jsonBody["user_name"], userID).Find(&conflictUser).Error; err != nil {
-			ginx.InternalError(c, nil)
+		if err := ginx.DB(c, di.DemoDB()).Model(&model.TUsers{}).Where("user_name = ? AND user_id != ?", jsonBody["user_name"], userID).Find(&conflictUser).Error; err != nil {
+			ginx.Fail(c, err)
 			return
 		}
 		if conflictUser.UserID > 0 {
-			ginx.Error(c, 400, "UserConflict", "用户名已存在")
+			ginx.Fail(c, consts.ErrUserConflict)
 			return
 		}
 	}
@@ -195,10 +228,14 @@ func (account) PutUsersByID(c *gin.Context) {
 		jsonBody["password"] = gox.PasswordHash(password)
 	}
 
-	if err := di.DemoDB().Model(&model.TUsers{}).Where("user_id = ?", userID).Updates(jsonBody).Error; err != nil {
-		ginx.InternalError(c, nil)
+	if err := ginx.DB(c, di.DemoDB()).Model(&model.TUsers{}).Where("user_id = ?", userID).Updates(jsonBody).Error; err != nil {
+		ginx.Fail(c, err)
 		return
 	}
+	// 用户详情缓存失效, 数据已提交, 客户端断开或超时也要执行
+	if err := service.ResponseCache.Invalidate(context.WithoutCancel(c.Request.Context()), fmt.Sprintf(consts.CacheTagUser, userID)); err != nil {
+		logx.L(c.Request.Context()).Warn("用户详情缓存失效失败, 缓存到期前可能返回旧数据", zap.Any("user_id", userID))
+	}
 
 	ginx.Success(c, 200, nil)
 }
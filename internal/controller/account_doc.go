@@ -0,0 +1,63 @@
+// Package controller API 控制器
+package controller
+
+import (
+	"go-demo/internal/consts"
+	"go-demo/internal/model"
+	"go-demo/pkg/ginx"
+)
+
+// 接口文档 DEMO, 参数模式与控制器共用
+func init() {
+	ginx.Doc(Account.PostUserLogin, ginx.Operation{
+		Summary:    "登录",
+		Tags:       []string{"账号"},
+		BodyStruct: userLoginRequest{},
+		Response: struct {
+			UserID int64  `json:"user_id"`
+			Token  string `json:"token"`
+		}{},
+		Errors: []*ginx.AppError{consts.ErrUserInvalid, consts.ErrTooManyRequests},
+	})
+	ginx.Doc(Account.DeleteUserLogout, ginx.Operation{
+		Summary: "退出登录",
+		Tags:    []string{"账号"},
+		Auth:    true,
+		Status:  204,
+		Errors:  []*ginx.AppError{consts.ErrUserUnauthorized},
+	})
+	ginx.Doc(Account.GetUsers, ginx.Operation{
+		Summary:  "用户列表",
+		Tags:     []string{"用户"},
+		Query:    getUsersQueries,
+		Paging:   true,
+		Response: userListItem{},
+	})
+	ginx.Doc(Account.GetUsersByID, ginx.Operation{
+		Summary:     "用户详情",
+		Description: "响应缓存 10 分钟, 修改用户信息后失效. 请求头 If-None-Match 与 ETag 一致时输出 304.",
+		Tags:        []string{"用户"},
+		Path:        []string{"user_id:用户id:+integer"},
+		Header:      []string{"If-None-Match:上次响应的 ETag:string"},
+		Response:    model.TUsers{},
+		Errors:      []*ginx.AppError{consts.ErrUserNotFound},
+	})
+	ginx.Doc(Account.PostUsers, ginx.Operation{
+		Summary: "批量新增用户",
+		Tags:    []string{"用户"},
+		Header:  []string{"Idempotency-Key:幂等键, 重试时响应重放:string"},
+		Body:    postUsersBody,
+		Status:  201,
+		Response: struct {
+			OKCount int64 `json:"ok_count"`
+		}{},
+		Errors: []*ginx.AppError{consts.ErrIdempotencyInFlight, consts.ErrIdempotencyKeyMismatch},
+	})
+	ginx.Doc(Account.PutUsersByID, ginx.Operation{
+		Summary: "修改用户信息",
+		Tags:    []string{"用户"},
+		Path:    []string{"user_id:用户id:+integer"},
+		Body:    putUsersByIDBody,
+		Errors:  []*ginx.AppError{consts.ErrParamRequired, consts.ErrUserNotFound, consts.ErrUserConflict},
+	})
+}
@@ -2,8 +2,15 @@
 package cron
 
 import (
+	"context"
+	"fmt"
+
 	"go-demo/config/di"
+	"go-demo/internal/consts"
 	"go-demo/internal/model"
+	"go-demo/internal/service"
+
+	"go.uber.org/zap"
 )
 
 // 用户相关计划任务 DEMO 这里定义一个空结构体用于为大量的 cron 方法做分类
@@ -20,5 +27,16 @@ func (user) DeleteUsers(userCount int) {
 	if err := di.DemoDB().Model(&model.TUsers{}).Select("user_id").Order("user_id").Limit(userCount).Find(&userIDs).Error; err != nil {
 		return
 	}
-	di.DemoDB().Where("user_id IN ?", userIDs).Delete(&model.TUsers{})
+	if err := di.DemoDB().Where("user_id IN ?", userIDs).Delete(&model.TUsers{}).Error; err != nil {
+		return
+	}
+
+	// 用户详情缓存失效
+	tags := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		tags = append(tags, fmt.Sprintf(consts.CacheTagUser, userID))
+	}
+	if err := service.ResponseCache.Invalidate(context.Background(), tags...); err != nil {
+		di.Logger().Warn("用户详情缓存失效失败, 缓存到期前可能返回旧数据", zap.Int64s("user_ids", userIDs))
+	}
 }
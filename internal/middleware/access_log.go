@@ -0,0 +1,150 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-demo/config"
+	"go-demo/config/di"
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// accessLogWriter 记录响应内容, 最多 limit 字节
+type accessLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *accessLogWriter) Write(data []byte) (int, error) {
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		w.body.Write(data[:min(remain, len(data))])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *accessLogWriter) WriteString(s string) (int, error) {
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		w.body.WriteString(s[:min(remain, len(s))])
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// accessLogIDsKey 访问日志登录用户 id context 键
+type accessLogIDsKey struct{}
+
+// accessLogIDs 访问日志登录用户 id, 由 JWTParse() 写入
+//
+//	超时后处理函数仍在 gin-timeout 的 Goroutine 中执行, 与其共享 c.Keys, 所以通过 context 传递.
+type accessLogIDs struct {
+	userID  atomic.Int64
+	adminID atomic.Int64
+}
+
+// AccessLog 访问日志
+//
+//	JSON 格式记录到 di.AccessLogger(): 请求方法/路由/状态码/耗时/字节数/客户端 IP/用户 id/请求 ID.
+//	按配置 access_log_body_sample 采样记录请求头与请求/响应内容, access_log_redact 中的字段脱敏. 需在 RequestID() 之后使用.
+func AccessLog() gin.HandlerFunc {
+	sample := config.GetFloat64("access_log_body_sample")
+	limit := config.GetInt("access_log_body_limit")
+	redactor := logx.NewRedactor(config.GetStringSlice("access_log_redact")...)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		ids := &accessLogIDs{}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), accessLogIDsKey{}, ids))
+
+		// 采样记录请求/响应内容
+		sampled := sample > 0 && rand.Float64() < sample
+		var reqBody []byte
+		var writer *accessLogWriter
+		if sampled {
+			if c.Request.Body != nil && !strings.HasPrefix(c.ContentType(), "multipart/") {
+				reqBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1)) // 仅读取需要记录的部分, 多读 1 字节用于判断截断
+				c.Request.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), c.Request.Body}
+			}
+			writer = &accessLogWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		query := c.Request.URL.Query()
+		fields := []zap.Field{
+			zap.String("request_id", logx.RequestID(c.Request.Context())),
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("query", redactor.Values(query)),
+			zap.Int("status", c.Writer.Status()),
+			zap.Float64("latency_ms", float64(time.Since(start).Microseconds())/1e3),
+			zap.Int64("bytes_in", c.Request.ContentLength),
+			zap.Int("bytes_out", max(c.Writer.Size(), 0)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+		}
+		if userID := ids.userID.Load(); userID > 0 {
+			fields = append(fields, zap.Int64("user_id", userID))
+		}
+		if adminID := ids.adminID.Load(); adminID > 0 {
+			fields = append(fields, zap.Int64("admin_id", adminID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+		if sampled {
+			fields = append(fields,
+				zap.Any("request_header", redactor.Header(c.Request.Header)),
+				zap.String("request_body", accessLogBody(redactor, c.ContentType(), reqBody, limit)),
+				zap.String("response_body", accessLogBody(redactor, writer.Header().Get("Content-Type"), writer.body.Bytes(), limit)),
+			)
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			di.AccessLogger().Error("access", fields...)
+		case status >= 400:
+			di.AccessLogger().Warn("access", fields...)
+		default:
+			di.AccessLogger().Info("access", fields...)
+		}
+	}
+}
+
+// accessLogBody 脱敏并截断请求/响应内容
+//
+//	JSON 与表单按字段脱敏, 其他文本原样记录, 二进制内容仅记录长度.
+func accessLogBody(redactor *logx.Redactor, contentType string, body []byte, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		body = redactor.JSON(body)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			body = []byte(redactor.Values(values))
+		}
+	case contentType != "" && !strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "xml"):
+		return "[" + strconv.Itoa(len(body)) + " bytes]"
+	}
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
@@ -9,6 +9,7 @@ import (
 	"go-demo/config"
 	"go-demo/config/di"
 	"go-demo/internal/consts"
+	"go-demo/internal/service"
 	"go-demo/pkg/ginx"
 	"go-demo/pkg/gox"
 
@@ -39,7 +40,7 @@ func JWTParse(userType string) gin.HandlerFunc {
 		}
 		// 白名单校验
 		key := fmt.Sprintf(consts.JWTLogin, userType, claims["jti"], gox.MD5(tokenString))
-		if n, err := di.JWTRedis().Exists(context.Background(), key).Result(); err != nil {
+		if n, err := di.JWTRedis().Exists(c.Request.Context(), key).Result(); err != nil {
 			di.Logger().Error(err.Error())
 			c.Next()
 			return
@@ -49,10 +50,17 @@ func JWTParse(userType string) gin.HandlerFunc {
 		}
 		// id 存入 Gin 上下文
 		id := cast.ToInt64(claims["jti"])
+		ids, _ := c.Request.Context().Value(accessLogIDsKey{}).(*accessLogIDs) // 访问日志
 		if userType == consts.UserJWT {
 			c.Set("userID", id) // 后续的处理函数可以用过 c.GetInt64("userID") 来获取当前请求的用户 id
+			if ids != nil {
+				ids.userID.Store(id)
+			}
 		} else if userType == consts.AdminJWT {
 			c.Set("adminID", id) // 后续的处理函数可以用过 c.GetInt64("adminID") 来获取当前请求的用户 id
+			if ids != nil {
+				ids.adminID.Store(id)
+			}
 		}
 		c.Next()
 	}
@@ -64,7 +72,58 @@ func JWTParse(userType string) gin.HandlerFunc {
 func UserAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.GetInt64("userID") == 0 {
-			ginx.Error(c, 401, "UserUnauthorized", "您未登录或登录已过期, 请重新登录")
+			ginx.Fail(c, consts.ErrUserUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminAuth 管理员鉴权
+//
+//	登录即可.
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetInt64("adminID") == 0 {
+			ginx.Fail(c, consts.ErrUserUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission 权限鉴权
+//
+//	需拥有全部权限, 管理员与用户均按 t_user_roles 中的角色授权. 需在 JWTParse() 之后使用, 未登录输出 401, 无权限输出 403.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return requirePermission(service.Permission.Has, permissions)
+}
+
+// RequireAnyPermission 权限鉴权
+//
+//	拥有任一权限即可, 其他同 RequirePermission().
+func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return requirePermission(service.Permission.HasAny, permissions)
+}
+
+// requirePermission 权限鉴权
+func requirePermission(has func(ctx context.Context, userType string, id int64, required ...string) (bool, error), permissions []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userType, id := consts.AdminJWT, c.GetInt64("adminID")
+		if id == 0 {
+			userType, id = consts.UserJWT, c.GetInt64("userID")
+		}
+		if id == 0 {
+			ginx.Fail(c, consts.ErrUserUnauthorized)
+			return
+		}
+		ok, err := has(c.Request.Context(), userType, id, permissions...)
+		if err != nil {
+			ginx.Fail(c, err)
+			return
+		}
+		if !ok {
+			ginx.Fail(c, consts.ErrPermissionDenied)
 			return
 		}
 		c.Next()
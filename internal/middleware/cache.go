@@ -0,0 +1,216 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-demo/config/di"
+	"go-demo/internal/consts"
+	"go-demo/internal/service"
+	"go-demo/pkg/ginx"
+	"go-demo/pkg/gox"
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/cache/v9"
+	"github.com/spf13/cast"
+)
+
+// CacheOptions 响应缓存选项
+type CacheOptions struct {
+	TTL     time.Duration                 // 服务端缓存时长, 最长 consts.ResponseCacheMaxTTL
+	MaxAge  time.Duration                 // 客户端缓存时长, 0 表示每次通过 ETag 协商
+	Query   []string                      // 参与缓存键的 query 参数白名单, 其他参数忽略
+	PerUser bool                          // 按登录用户隔离, 需在 JWTParse() 之后使用
+	Tags    func(c *gin.Context) []string // 失效标签, 见 service.ResponseCache.Invalidate()
+}
+
+// CacheTagByParam 按路由整数参数生成失效标签, format 见 consts.CacheTag*
+//
+//	参数转为整数, 与 Invalidate() 传入的 ID 一致, 比如 /users/007 与 /users/7 对应同一标签.
+func CacheTagByParam(format, param string) func(c *gin.Context) []string {
+	return func(c *gin.Context) []string {
+		return []string{fmt.Sprintf(format, cast.ToInt64(c.Param(param)))}
+	}
+}
+
+// cachedResponse 缓存的响应
+type cachedResponse struct {
+	Header http.Header // 处理器设置的响应头
+	Body   []byte
+	ETag   string
+}
+
+// responseCacheWriter 缓冲响应, 处理完成后再输出以便设置 ETag
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseCacheWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *responseCacheWriter) WriteHeaderNow() {}
+
+func (w *responseCacheWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *responseCacheWriter) Status() int {
+	return w.status
+}
+
+func (w *responseCacheWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *responseCacheWriter) Written() bool {
+	return false
+}
+
+func (w *responseCacheWriter) Flush() {}
+
+// ResponseCache GET 响应缓存
+//
+//	缓存键由 locale + 路径 + 白名单 query 参数 (+ 用户 id) + 标签版本组成, 仅缓存 200 响应, Redis + 本地两级缓存.
+//	响应附加 ETag/Cache-Control/X-Cache(HIT/MISS), 请求头 If-None-Match 匹配时输出 304.
+//	数据变更后调用 service.ResponseCache.Invalidate() 按标签失效. 不适用于流式输出(比如导出).
+func ResponseCache(opts CacheOptions) gin.HandlerFunc {
+	query := slices.Clone(opts.Query)
+	slices.Sort(query)
+	opts.TTL = min(opts.TTL, consts.ResponseCacheMaxTTL)
+	cacheControl := "public"
+	if opts.PerUser {
+		cacheControl = "private"
+	}
+	if opts.MaxAge > 0 {
+		cacheControl += ", max-age=" + strconv.Itoa(int(opts.MaxAge.Seconds()))
+	} else {
+		cacheControl += ", no-cache"
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		var tags []string
+		if opts.Tags != nil {
+			tags = opts.Tags(c)
+		}
+		versions, err := service.ResponseCache.Versions(ctx, tags)
+		if err != nil { // Redis 故障时不使用缓存
+			logx.L(ctx).Warn(err.Error())
+			c.Next()
+			return
+		}
+
+		// 缓存键
+		parts := []string{ginx.Locale(c), c.Request.URL.Path}
+		for _, name := range query {
+			parts = append(parts, name+"="+strings.Join(c.QueryArray(name), ","))
+		}
+		if opts.PerUser {
+			parts = append(parts, "user="+strconv.FormatInt(c.GetInt64("userID"), 10), "admin="+strconv.FormatInt(c.GetInt64("adminID"), 10))
+		}
+		for i, tag := range tags {
+			parts = append(parts, tag+"@"+versions[i])
+		}
+		key := fmt.Sprintf(consts.ResponseCache, gox.MD5(strings.Join(parts, "\n")))
+
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if opts.PerUser {
+			c.Writer.Header().Add("Vary", "Authorization")
+		}
+
+		// 命中缓存
+		cached := cachedResponse{}
+		if err := di.ResponseCache().Get(ctx, key, &cached); err == nil {
+			for name, values := range cached.Header {
+				c.Writer.Header()[name] = values
+			}
+			writeCachedResponse(c, &cached, cacheControl, "HIT")
+			c.Abort()
+			return
+		} else if !errors.Is(err, cache.ErrCacheMiss) {
+			logx.L(ctx).Warn(err.Error())
+		}
+
+		// 未命中, 记录处理前的响应头, 仅缓存处理器设置的响应头
+		before := c.Writer.Header().Clone()
+		writer := &responseCacheWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.status != http.StatusOK {
+			c.Status(writer.status)
+			_, _ = c.Writer.Write(writer.body.Bytes())
+			return
+		}
+
+		cached = cachedResponse{
+			Header: http.Header{},
+			Body:   writer.body.Bytes(),
+			ETag:   `"` + gox.MD5(writer.body.String()) + `"`,
+		}
+		for name, values := range c.Writer.Header() {
+			if idempotencySkipHeaders[http.CanonicalHeaderKey(name)] || name == "Vary" {
+				continue
+			}
+			if slices.Equal(before[name], values) {
+				continue
+			}
+			cached.Header[name] = values
+		}
+		if err := di.ResponseCache().Set(&cache.Item{
+			Ctx:   context.WithoutCancel(ctx),
+			Key:   key,
+			Value: &cached,
+			TTL:   opts.TTL,
+		}); err != nil {
+			logx.L(ctx).Warn(err.Error())
+		} else {
+			service.ResponseCache.Touch(context.WithoutCancel(ctx), tags, versions)
+		}
+		writeCachedResponse(c, &cached, cacheControl, "MISS")
+	}
+}
+
+// writeCachedResponse 输出缓存的响应, If-None-Match 匹配时输出 304
+func writeCachedResponse(c *gin.Context, cached *cachedResponse, cacheControl, state string) {
+	c.Header("Cache-Control", cacheControl)
+	c.Header("ETag", cached.ETag)
+	c.Header("X-Cache", state)
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == cached.ETag {
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+	}
+	c.Status(http.StatusOK)
+	_, _ = c.Writer.Write(cached.Body)
+}
@@ -0,0 +1,196 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-demo/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// CORSPolicy 跨域策略
+type CORSPolicy struct {
+	AllowOrigins     []string // 允许的 Origin, * 允许全部(不可与 AllowCredentials 同时使用), 支持通配符(https://*.example.com), ~ 开头为正则
+	AllowMethods     []string // 允许的请求方法
+	AllowHeaders     []string // 允许的请求头, * 允许预检请求声明的全部请求头
+	ExposeHeaders    []string // 客户端可读取的响应头
+	AllowCredentials bool     // 是否允许携带 Cookie 等凭证
+	MaxAge           int      // 预检结果缓存秒数
+
+	allowAll bool             // AllowOrigins 包含 *
+	origins  []*regexp.Regexp // 编译后的 AllowOrigins
+}
+
+// compile 编译 Origin 匹配规则
+//
+//	Origin * 与 AllowCredentials 同时配置时等于任意站点都可以携带凭证访问, 记录错误并关闭 AllowCredentials.
+func (p *CORSPolicy) compile() {
+	p.allowAll = false
+	p.origins = p.origins[:0:0]
+	for _, origin := range p.AllowOrigins {
+		var expr string
+		switch {
+		case origin == "*":
+			p.allowAll = true
+			continue
+		case strings.HasPrefix(origin, "~"):
+			expr = origin[1:]
+		default:
+			expr = "^" + strings.ReplaceAll(regexp.QuoteMeta(origin), `\*`, `[^/:]+`) + "$"
+		}
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			zap.L().Error("CORS Origin 规则错误: "+origin, zap.Error(err))
+			continue
+		}
+		p.origins = append(p.origins, re)
+	}
+	if p.allowAll && p.AllowCredentials {
+		zap.L().Error("CORS 不允许同时配置 Origin * 与 allow_credentials, 已关闭 allow_credentials")
+		p.AllowCredentials = false
+	}
+}
+
+// allowed Origin 是否允许
+func (p *CORSPolicy) allowed(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	for _, re := range p.origins {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS 跨域处理
+//
+//	策略读取配置项 cors, 其中 routes 按路径前缀覆盖默认策略.
+func CORS() gin.HandlerFunc {
+	cfg := config.GetStringMap("cors")
+	policy := corsPolicyFromConfig(CORSPolicy{}, cfg)
+	routes := map[string]CORSPolicy{}
+	for prefix, route := range cast.ToStringMap(cfg["routes"]) {
+		routes[prefix] = corsPolicyFromConfig(policy, cast.ToStringMap(route))
+	}
+
+	return CORSWith(policy, routes)
+}
+
+// CORSWith 按策略跨域处理
+//
+//	routes 按路径前缀覆盖默认策略, 最长前缀优先. 不允许的 Origin 不输出跨域响应头, 其预检请求输出 403.
+func CORSWith(policy CORSPolicy, routes map[string]CORSPolicy) gin.HandlerFunc {
+	policy.compile()
+	compiled := make(map[string]CORSPolicy, len(routes))
+	prefixes := make([]string, 0, len(routes))
+	for prefix, route := range routes {
+		route.compile()
+		compiled[prefix] = route
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { // 最长前缀优先
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	return func(c *gin.Context) {
+		p := &policy
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				route := compiled[prefix]
+				p = &route
+				break
+			}
+		}
+
+		wildcard := p.allowAll // 输出 *, 响应与 Origin 无关, 此时不允许携带凭证
+		if !wildcard {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != ""
+		if !p.allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(403)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if wildcard {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if p.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if len(p.ExposeHeaders) > 0 {
+				c.Header("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+			}
+			c.Next()
+			return
+		}
+
+		// 预检请求
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", strings.Join(p.AllowMethods, ", "))
+		allowHeaders := strings.Join(p.AllowHeaders, ", ")
+		if allowHeaders == "*" {
+			allowHeaders = c.GetHeader("Access-Control-Request-Headers")
+		}
+		if allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+		}
+		if p.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(p.MaxAge))
+		}
+		c.AbortWithStatus(204)
+	}
+}
+
+// corsPolicyFromConfig 由配置生成策略, 未配置的项沿用 base
+func corsPolicyFromConfig(base CORSPolicy, cfg map[string]any) CORSPolicy {
+	policy := CORSPolicy{
+		AllowOrigins:     base.AllowOrigins,
+		AllowMethods:     base.AllowMethods,
+		AllowHeaders:     base.AllowHeaders,
+		ExposeHeaders:    base.ExposeHeaders,
+		AllowCredentials: base.AllowCredentials,
+		MaxAge:           base.MaxAge,
+	}
+	if value, ok := cfg["allow_origins"]; ok {
+		policy.AllowOrigins = cast.ToStringSlice(value)
+	}
+	if value, ok := cfg["allow_methods"]; ok {
+		policy.AllowMethods = cast.ToStringSlice(value)
+	}
+	if value, ok := cfg["allow_headers"]; ok {
+		policy.AllowHeaders = cast.ToStringSlice(value)
+	}
+	if value, ok := cfg["expose_headers"]; ok {
+		policy.ExposeHeaders = cast.ToStringSlice(value)
+	}
+	if value, ok := cfg["allow_credentials"]; ok {
+		policy.AllowCredentials = cast.ToBool(value)
+	}
+	if value, ok := cfg["max_age"]; ok {
+		policy.MaxAge = cast.ToInt(value)
+	}
+
+	return policy
+}
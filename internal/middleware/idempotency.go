@@ -0,0 +1,192 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-demo/config"
+	"go-demo/config/di"
+	"go-demo/internal/consts"
+	"go-demo/pkg/ginx"
+	"go-demo/pkg/gox"
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cast"
+)
+
+// idempotencyRetryable 可以重试的状态码, 不保存响应, 5xx 同样不保存
+var idempotencyRetryable = map[int]bool{
+	http.StatusRequestTimeout:  true,
+	http.StatusTooManyRequests: true,
+	499:                        true, // ginx.ErrCanceled
+}
+
+// idempotencySkipHeaders 不重放的响应头, 由各请求自行生成
+var idempotencySkipHeaders = map[string]bool{
+	"X-Request-Id":        true,
+	"Ratelimit-Limit":     true,
+	"Ratelimit-Remaining": true,
+	"Ratelimit-Reset":     true,
+	"Retry-After":         true,
+	"Set-Cookie":          true,
+}
+
+// idempotencyRecord 幂等记录
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"` // 请求指纹 md5(method+uri+body)
+	Done        bool        `json:"done"`        // 是否处理完成
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// idempotencyWriter 记录响应内容
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等处理
+//
+//	请求头带 Idempotency-Key 时, 首次请求的响应(状态码/响应头/响应体)在 Redis 中保存 ttl 时长, 重试时直接重放并附加响应头 Idempotent-Replayed: true;
+//	首次请求处理中重试输出 409, 同一幂等键用于不同请求输出 422. 5xx/408/429/499 响应不保存, 可以重试.
+//	处理中记录有效期为超时时间 timeout 配置加 10 秒, 请求异常中断时到期后允许重试; 请求体超过 idempotency_body_limit 输出 413.
+//	幂等键按登录用户隔离, 未登录时按 IP+User-Agent 隔离. 不带请求头时不做处理.
+func Idempotency(ttl time.Duration) gin.HandlerFunc {
+	inFlightTTL := time.Duration(config.GetInt("timeout"))*time.Second + 10*time.Second
+	bodyLimit := int64(config.GetInt("idempotency_body_limit"))
+
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > 255 {
+			ginx.Fail(c, ginx.ErrParamInvalid)
+			return
+		}
+
+		// 请求指纹
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, bodyLimit))
+		if err != nil {
+			if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+				ginx.Fail(c, consts.ErrRequestTooLarge)
+			} else {
+				ginx.Fail(c, ginx.ErrParamInvalid.Wrap(err))
+			}
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := gox.MD5(c.Request.Method + ":" + c.Request.URL.RequestURI() + ":" + string(body))
+
+		// 优先取用户 id 作为唯一标识, 如果没有则取 ip+agent 作为唯一标识
+		uid := ""
+		if userID := c.GetInt64("userID"); userID > 0 {
+			uid = cast.ToString(userID)
+		} else if adminID := c.GetInt64("adminID"); adminID > 0 {
+			uid = cast.ToString(adminID)
+		} else {
+			uid = c.ClientIP() + ":" + c.Request.UserAgent()
+		}
+		key := fmt.Sprintf(consts.Idempotency, gox.MD5(uid+":"+c.Request.Method+":"+c.FullPath()+":"+idempotencyKey))
+
+		ctx := c.Request.Context()
+		inFlight, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		ok, err := di.CacheRedis().SetNX(ctx, key, inFlight, inFlightTTL).Result()
+		if err != nil {
+			ginx.Fail(c, err)
+			return
+		}
+		if !ok {
+			replayIdempotency(c, key, fingerprint)
+			return
+		}
+
+		// 首次请求
+		done := false
+		defer func() {
+			if !done { // panic 或可重试的响应, 删除记录允许重试
+				di.CacheRedis().Del(context.WithoutCancel(ctx), key)
+			}
+		}()
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if status := c.Writer.Status(); status >= 500 || idempotencyRetryable[status] {
+			return
+		}
+		header := http.Header{}
+		for name, values := range c.Writer.Header() {
+			if !idempotencySkipHeaders[http.CanonicalHeaderKey(name)] {
+				header[name] = values
+			}
+		}
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      c.Writer.Status(),
+			Header:      header,
+			Body:        writer.body.Bytes(),
+		})
+		if err := di.CacheRedis().Set(context.WithoutCancel(ctx), key, record, ttl).Err(); err != nil {
+			logx.L(ctx).Error(err.Error())
+			return
+		}
+		done = true
+	}
+}
+
+// replayIdempotency 重放已保存的响应
+func replayIdempotency(c *gin.Context, key, fingerprint string) {
+	data, err := di.CacheRedis().Get(c.Request.Context(), key).Bytes()
+	if err == redis.Nil { // 首次请求刚好失败删除了记录
+		ginx.Fail(c, consts.ErrIdempotencyInFlight)
+		return
+	}
+	if err != nil {
+		ginx.Fail(c, err)
+		return
+	}
+	record := idempotencyRecord{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		ginx.Fail(c, err)
+		return
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		ginx.Fail(c, consts.ErrIdempotencyKeyMismatch)
+	case !record.Done:
+		ginx.Fail(c, consts.ErrIdempotencyInFlight)
+	default:
+		for name, values := range record.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Status(record.Status)
+		_, _ = c.Writer.Write(record.Body)
+		c.Abort()
+	}
+}
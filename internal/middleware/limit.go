@@ -2,14 +2,18 @@
 package middleware
 
 import (
-	"context"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"time"
 
 	"go-demo/config/di"
 	"go-demo/internal/consts"
 	"go-demo/pkg/ginx"
 	"go-demo/pkg/gox"
+	"go-demo/pkg/limitx"
+	"go-demo/pkg/metricx"
 
 	"github.com/gin-gonic/gin"
 	"github.com/juju/ratelimit"
@@ -18,52 +22,159 @@ import (
 )
 
 // QPSLimit QPS 限流
+//
+//	本地令牌桶, 限额按实例计算, 作为实例过载保护, 不依赖 Redis. 按 IP/用户/路由限流使用 RateLimit().
+//	qps <= 0 表示不限流.
 func QPSLimit(qps int) gin.HandlerFunc {
+	if qps <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
 	quantum := cast.ToInt64(qps)
 	bucket := ratelimit.NewBucketWithQuantum(time.Second, quantum, quantum)
 	return func(c *gin.Context) {
 		if bucket.TakeAvailable(1) < 1 {
-			ginx.Error(c, 429, "TooManyRequests", "服务繁忙, 请稍后重试")
+			metricx.RateLimited("qps")
+			ginx.Fail(c, consts.ErrTooManyRequests)
 			return
 		}
 		c.Next()
 	}
 }
 
-// SubmitLimit 提交频率限制
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Name  string                      // 策略名, 区分不同策略的限流键
+	Key   func(c *gin.Context) string // 限流对象, 返回空字符串时跳过此策略
+	Limit limitx.Limit                // 限额
+}
+
+// LimitGlobal 全局限流, 所有实例所有请求共享限额
+//
+//	每个请求都要访问 Redis 同一个键, 实例过载保护使用 QPSLimit().
+func LimitGlobal(c *gin.Context) string {
+	return "global"
+}
+
+// LimitByIP 按客户端 IP 限流
+func LimitByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// LimitByUser 按登录用户限流, 未登录时跳过
+//
+//	需在 JWTParse() 之后使用.
+func LimitByUser(c *gin.Context) string {
+	if userID := c.GetInt64("userID"); userID > 0 {
+		return "user:" + cast.ToString(userID)
+	}
+	if adminID := c.GetInt64("adminID"); adminID > 0 {
+		return "admin:" + cast.ToString(adminID)
+	}
+	return ""
+}
+
+// LimitByRoute 按路由限流
+func LimitByRoute(c *gin.Context) string {
+	return c.Request.Method + ":" + c.FullPath()
+}
+
+// LimitByAPIKey 按请求头 X-API-Key 限流, 没有时跳过
+func LimitByAPIKey(c *gin.Context) string {
+	return c.GetHeader("X-API-Key")
+}
+
+// RateLimit 分布式限流
 //
-//	主要用于防重.
-func SubmitLimit() gin.HandlerFunc {
+//	按顺序检查各策略, 任一策略超限即输出 429 并设置 Retry-After. 限额在多实例间共享, Redis 不可用时回退到本地令牌桶.
+//	响应头 RateLimit-Limit/RateLimit-Remaining/RateLimit-Reset 取剩余次数最少的策略.
+func RateLimit(policies ...RateLimitPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		uid := "" // md5(id+method+path)
-		// 优先取用户 id 作为唯一标识, 如果没有则取 ip+agent 作为唯一标识
-		if userID := c.GetInt64("userID"); userID > 0 {
-			uid = cast.ToString(userID)
-		} else if adminID := c.GetInt64("adminID"); adminID > 0 {
-			uid = cast.ToString(adminID)
-		} else {
-			uid = c.ClientIP() + ":" + c.Request.UserAgent()
+		var header *limitx.Result
+		var denied string // 超限的策略
+		for _, policy := range policies {
+			key := policy.Key(c)
+			if key == "" || policy.Limit.Rate <= 0 { // 限额为0表示不限流
+				continue
+			}
+			result := di.Limiter().Allow(c.Request.Context(), fmt.Sprintf(consts.RateLimit, policy.Name, gox.MD5(key)), policy.Limit)
+			if header == nil || !result.Allowed || result.Remaining < header.Remaining {
+				header = &result
+			}
+			if !result.Allowed {
+				denied = policy.Name
+				break
+			}
 		}
-		uid = gox.MD5(uid + ":" + c.Request.Method + ":" + c.Request.URL.Path)
-		key := fmt.Sprintf(consts.SubmitLimit, uid)
-		ok, err := di.CacheRedis().SetNX(context.Background(), key, 1, 2*time.Second).Result() // 2秒/次
-		if err != nil {
-			ginx.InternalError(c, err)
+		if header == nil {
+			c.Next()
 			return
 		}
-		if !ok {
-			ginx.Error(c, 429, "SubmitLimit", "手快了, 请稍后~~")
+
+		c.Header("RateLimit-Limit", strconv.Itoa(header.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(header.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(header.ResetAfter)))
+		if !header.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(header.RetryAfter)))
+			metricx.RateLimited(denied)
+			ginx.Fail(c, consts.ErrTooManyRequests)
 			return
 		}
 		c.Next()
 	}
 }
 
+// ceilSeconds 时长向上取整为秒数
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
 // Timeout 超时控制
+//
+//	超时信息按请求 locale 与错误输出格式生成, 仅在超时时生成.
+//	gin-timeout 会缓冲全部响应, 导出请求(带 export 参数)跳过超时控制以便流式输出, 见 ginx.Paginate().
+//	之后的处理在独立 Goroutine 中执行, Recovery() 应在其后注册, 见 Recovery().
 func Timeout(t time.Duration) gin.HandlerFunc {
-	return timeout.Timeout(
-		timeout.WithTimeout(t),
-		timeout.WithErrorHttpCode(408), // optional
-		timeout.WithDefaultMsg(`{"code": "RequestTimeout", "message":"请求超时, 请稍后重试"}`), // optional
-	)
+	return func(c *gin.Context) {
+		if c.Query("export") != "" {
+			c.Next()
+			return
+		}
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, request: c.Request}
+		timeout.Timeout(
+			timeout.WithTimeout(t),
+			timeout.WithErrorHttpCode(408), // optional
+			timeout.WithDefaultMsg(""),     // 超时信息由 timeoutWriter 输出
+		)(c)
+	}
+}
+
+// timeoutWriter 输出超时信息
+//
+//	gin-timeout 超时时先写入 408 再写入空的默认信息, 此时按请求生成超时信息并设置 Content-Type.
+//	超时时处理器仍在运行并共享 Gin 上下文键值, 所以仅依据请求生成, 不读写原上下文.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	request *http.Request
+	body    []byte // 待输出的超时信息
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code == 408 && w.Header().Get("Content-Type") == "" {
+		w.body = ginx.ErrorBody(&gin.Context{Request: w.request}, w.Header(), ginx.ErrTimeout.HTTPCode, ginx.ErrTimeout.Code, ginx.ErrTimeout.Message)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.body != nil && len(data) == 0 {
+		body := w.body
+		w.body = nil
+		return w.ResponseWriter.Write(body)
+	}
+	return w.ResponseWriter.Write(data)
 }
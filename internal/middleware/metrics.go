@@ -0,0 +1,23 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"go-demo/pkg/metricx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics HTTP 指标
+//
+//	按请求方法/路由模板/状态码记录请求数与耗时, 未匹配的路由记为 unmatched.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := metricx.HTTPStart()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(c.Request.Method, route, c.Writer.Status())
+	}
+}
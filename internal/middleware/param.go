@@ -0,0 +1,18 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"go-demo/pkg/ginx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateAll 开启参数完整校验模式
+//
+//	一次性输出所有参数错误, 适用于表单类接口. 详见 ginx.ValidateAll().
+func ValidateAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ginx.ValidateAll(c)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+// Package middleware Gin 中间件
+package middleware
+
+import (
+	"regexp"
+
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+var requestIDRegexp = regexp.MustCompile(`^[\w\-.:]{1,64}$`) // 客户端传入的请求 ID 格式
+
+// RequestID 请求 ID
+//
+//	优先使用请求头 X-Request-ID, 没有或格式不正确时生成 UUID. 请求 ID 存放在 c.Request.Context() 中, 并通过响应头 X-Request-ID 返回.
+//	日志使用 logx.L(ctx), GORM 使用 WithContext(ctx), 队列使用 queuex 传入 ctx 即可携带请求 ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !requestIDRegexp.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Request = c.Request.WithContext(logx.WithRequestID(c.Request.Context(), requestID))
+		c.Set("requestID", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
@@ -2,40 +2,35 @@
 package middleware
 
 import (
-	"errors"
-	"fmt"
+	"strconv"
 
+	"go-demo/config/di"
 	"go-demo/pkg/ginx"
+	"go-demo/pkg/panicx"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Recovery 主 Goroutine 中 panic 处理
+// Recovery panic 处理
+//
+//	panic 连同栈信息/请求 ID/路由/用户 id 通过 di.Panic() 上报, 输出 500.
+//	应在 Timeout() 之后注册: 之后的处理在独立 Goroutine 中执行, 已超时的请求发生 panic 时 gin-timeout 不再转发, 在其中 recover 才能上报且栈信息准确.
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				ginx.InternalError(c, errors.New(fmt.Sprint(r)))
+				event := panicx.Event{
+					Origin: panicx.OriginHTTP,
+					Route:  c.Request.Method + " " + c.FullPath(),
+					UserID: c.GetInt64("userID"),
+				}
+				if adminID := c.GetInt64("adminID"); adminID > 0 {
+					event.Extra = map[string]string{"admin_id": strconv.FormatInt(adminID, 10)}
+				}
+				di.Panic().Capture(c.Request.Context(), r, event)
+				ginx.InternalError(c, nil)
 			}
 		}()
 		c.Next()
 	}
 }
-
-// CORS 跨域处理
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if c.Request.Header.Get("Origin") != "" {
-			c.Header("Access-Control-Allow-Origin", c.Request.Header.Get("Origin"))
-			c.Header("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Length, Accept, Origin, Host, Connection, Accept-Encoding, Accept-Language, DNT, Keep-Alive, User-Agent, If-Modified-Since, Cache-Control, Content-Type, Pragma")
-			c.Header("Access-Control-Max-Age", "1728000")
-			c.Header("Access-Control-Allow-Credentials", "false")
-			if c.Request.Method == "OPTIONS" {
-				ginx.Success(c, 200, nil)
-				return
-			}
-		}
-		c.Next()
-	}
-}
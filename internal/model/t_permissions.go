@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// TPermissions 权限表
+type TPermissions struct {
+	PermissionID int64     `gorm:"primaryKey;column:permission_id;type:bigint;not null" json:"permission_id"`
+	Permission   string    `gorm:"unique;column:permission;type:varchar(100);not null;default:''" json:"permission"` // 权限标识, 比如 user:update
+	Description  string    `gorm:"column:description;type:varchar(255);not null;default:''" json:"description"`      // 描述
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *TPermissions) TableName() string {
+	return "t_permissions"
+}
+
+// TPermissionsColumns get sql column name.获取数据库列名
+var TPermissionsColumns = struct {
+	PermissionID string
+	Permission   string
+	Description  string
+	CreatedAt    string
+	UpdatedAt    string
+}{
+	PermissionID: "permission_id",
+	Permission:   "permission",
+	Description:  "description",
+	CreatedAt:    "created_at",
+	UpdatedAt:    "updated_at",
+}
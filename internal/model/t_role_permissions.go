@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// TRolePermissions 角色权限表
+type TRolePermissions struct {
+	RoleID       int64     `gorm:"primaryKey;column:role_id;type:bigint;not null" json:"role_id"`
+	PermissionID int64     `gorm:"primaryKey;index:permission_id;column:permission_id;type:bigint;not null" json:"permission_id"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *TRolePermissions) TableName() string {
+	return "t_role_permissions"
+}
+
+// TRolePermissionsColumns get sql column name.获取数据库列名
+var TRolePermissionsColumns = struct {
+	RoleID       string
+	PermissionID string
+	CreatedAt    string
+}{
+	RoleID:       "role_id",
+	PermissionID: "permission_id",
+	CreatedAt:    "created_at",
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// TRoles 角色表
+type TRoles struct {
+	RoleID      int64     `gorm:"primaryKey;column:role_id;type:bigint;not null" json:"role_id"`
+	RoleName    string    `gorm:"unique;column:role_name;type:varchar(50);not null;default:''" json:"role_name"` // 角色标识, 比如 admin
+	Description string    `gorm:"column:description;type:varchar(255);not null;default:''" json:"description"`   // 描述
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *TRoles) TableName() string {
+	return "t_roles"
+}
+
+// TRolesColumns get sql column name.获取数据库列名
+var TRolesColumns = struct {
+	RoleID      string
+	RoleName    string
+	Description string
+	CreatedAt   string
+	UpdatedAt   string
+}{
+	RoleID:      "role_id",
+	RoleName:    "role_name",
+	Description: "description",
+	CreatedAt:   "created_at",
+	UpdatedAt:   "updated_at",
+}
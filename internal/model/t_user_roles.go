@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// TUserRoles 用户角色表
+type TUserRoles struct {
+	UserType  string    `gorm:"primaryKey;column:user_type;type:varchar(20);not null;default:''" json:"user_type"` // 登录用户类型, user-用户, admin-管理员
+	UserID    int64     `gorm:"primaryKey;column:user_id;type:bigint;not null" json:"user_id"`
+	RoleID    int64     `gorm:"primaryKey;index:role_id;column:role_id;type:bigint;not null" json:"role_id"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName get sql table name.获取数据库表名
+func (m *TUserRoles) TableName() string {
+	return "t_user_roles"
+}
+
+// TUserRolesColumns get sql column name.获取数据库列名
+var TUserRolesColumns = struct {
+	UserType  string
+	UserID    string
+	RoleID    string
+	CreatedAt string
+}{
+	UserType:  "user_type",
+	UserID:    "user_id",
+	RoleID:    "role_id",
+	CreatedAt: "created_at",
+}
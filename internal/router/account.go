@@ -2,9 +2,12 @@
 package router
 
 import (
+	"time"
+
 	"go-demo/internal/consts"
 	"go-demo/internal/controller"
 	"go-demo/internal/middleware"
+	"go-demo/pkg/limitx"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,17 +17,24 @@ func Account(r *gin.Engine) {
 	accountGroup := r.Group("/account/v1", middleware.JWTParse(consts.UserJWT))
 	{
 		// 登录
-		accountGroup.POST("/login", middleware.SubmitLimit(), controller.Account.PostUserLogin)
+		accountGroup.POST("/login", middleware.RateLimit(middleware.RateLimitPolicy{
+			Name:  "login",
+			Key:   middleware.LimitByIP,
+			Limit: limitx.PerMinute(10),
+		}), controller.Account.PostUserLogin)
 		// 退出登录
 		accountGroup.DELETE("/logout", middleware.UserAuth(), controller.Account.DeleteUserLogout)
 
 		// 用户列表
 		accountGroup.GET("/users", controller.Account.GetUsers)
 		// 用户详情
-		accountGroup.GET("/users/:user_id", controller.Account.GetUsersByID)
+		accountGroup.GET("/users/:user_id", middleware.ResponseCache(middleware.CacheOptions{
+			TTL:  10 * time.Minute,
+			Tags: middleware.CacheTagByParam(consts.CacheTagUser, "user_id"),
+		}), controller.Account.GetUsersByID)
 		// 新增用户
-		accountGroup.POST("/users", middleware.SubmitLimit(), controller.Account.PostUsers)
+		accountGroup.POST("/users", middleware.Idempotency(24*time.Hour), controller.Account.PostUsers)
 		// 修改用户信息
-		accountGroup.PUT("/users/:user_id", controller.Account.PutUsersByID)
+		accountGroup.PUT("/users/:user_id", middleware.ValidateAll(), controller.Account.PutUsersByID)
 	}
 }
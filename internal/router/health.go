@@ -0,0 +1,16 @@
+// Package router API 路由
+package router
+
+import (
+	"go-demo/config/di"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health 健康检查路由, /healthz 存活检查, /readyz 就绪检查
+//
+//	handlers 为前置中间件, 比如限流.
+func Health(r *gin.Engine, handlers ...gin.HandlerFunc) {
+	r.GET("/healthz", append(handlers, gin.WrapF(di.Health().Liveness))...)
+	r.GET("/readyz", append(handlers, gin.WrapF(di.Health().Readiness))...)
+}
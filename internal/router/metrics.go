@@ -0,0 +1,15 @@
+// Package router API 路由
+package router
+
+import (
+	"go-demo/pkg/metricx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics Prometheus 指标路由
+//
+//	handlers 为前置中间件, 比如限流.
+func Metrics(r *gin.Engine, handlers ...gin.HandlerFunc) {
+	r.GET("/metrics", append(handlers, gin.WrapH(metricx.Handler()))...)
+}
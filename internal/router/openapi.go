@@ -0,0 +1,19 @@
+// Package router API 路由
+package router
+
+import (
+	"go-demo/pkg/ginx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIInfo 接口文档信息
+var APIInfo = ginx.OpenAPIInfo{
+	Title:   "go-demo API",
+	Version: "1.0.0",
+}
+
+// OpenAPI 接口文档路由, 文档由 ginx.Doc() 注册, 见 controller/account_doc.go
+func OpenAPI(r *gin.Engine) {
+	r.GET("/openapi.json", ginx.OpenAPIHandler(r, APIInfo))
+}
@@ -27,7 +27,7 @@ var Auth auth
 //	先生成 JWT, 再记录 redis 白名单.
 //	userType 为 JWT 登录用户类型, 集中在 consts/auth.go 中定义. id 为用户 id.
 //	返回字符串为 JWT token.
-func (auth) JWTLogin(userType string, id int64, userName string) (string, error) {
+func (auth) JWTLogin(ctx context.Context, userType string, id int64, userName string) (string, error) {
 	// JWT登录
 	loginTTL := 30 * 24 * time.Hour // 登录有效时长
 	claims := &jwt.RegisteredClaims{
@@ -50,7 +50,7 @@ func (auth) JWTLogin(userType string, id int64, userName string) (string, error)
 		return "", err
 	}
 	key := fmt.Sprintf(consts.JWTLogin, userType, claims.ID, gox.MD5(tokenString))
-	if err := di.JWTRedis().Set(context.Background(), key, payload, loginTTL).Err(); err != nil {
+	if err := di.JWTRedis().Set(ctx, key, payload, loginTTL).Err(); err != nil {
 		di.Logger().Error(err.Error())
 		return "", err
 	}
@@ -62,9 +62,9 @@ func (auth) JWTLogin(userType string, id int64, userName string) (string, error)
 //
 //	从 redis 白名单删除.
 //	userType 为 JWT 登录用户类型, 集中在 consts/auth.go 中定义. token 为 JWT token. id 为用户 id.
-func (auth) JWTLogout(userType, token string, id int64) error {
+func (auth) JWTLogout(ctx context.Context, userType, token string, id int64) error {
 	key := fmt.Sprintf(consts.JWTLogin, userType, id, gox.MD5(token))
-	if err := di.JWTRedis().Del(context.Background(), key).Err(); err != nil {
+	if err := di.JWTRedis().Del(ctx, key).Err(); err != nil {
 		di.Logger().Error(err.Error())
 		return err
 	}
@@ -0,0 +1,131 @@
+// Package service 内部应用业务原子级服务
+//
+//	需要公共使用的业务逻辑在这里实现.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-demo/config/di"
+	"go-demo/internal/consts"
+	"go-demo/internal/model"
+
+	"github.com/go-redis/cache/v9"
+)
+
+type permission struct{}
+
+var Permission permission
+
+// permissionTTL 权限缓存时长, 角色或权限变更后应调用 ClearCache()/ClearRoleCache() 立即生效
+const permissionTTL = 10 * time.Minute
+
+// List 用户拥有的权限
+//
+//	userType 为 JWT 登录用户类型, 集中在 consts/auth.go 中定义. 结果缓存在 Redis 中.
+func (permission) List(ctx context.Context, userType string, id int64) ([]string, error) {
+	permissions := make([]string, 0)
+	err := di.Cache().Once(&cache.Item{
+		Ctx:   ctx,
+		Key:   fmt.Sprintf(consts.Permissions, userType, id),
+		Value: &permissions,
+		TTL:   permissionTTL,
+		Do: func(*cache.Item) (any, error) {
+			result := make([]string, 0)
+			err := di.DemoDB().WithContext(ctx).Model(&model.TUserRoles{}).
+				Distinct("p.permission").
+				Joins("JOIN t_role_permissions rp ON rp.role_id = t_user_roles.role_id").
+				Joins("JOIN t_permissions p ON p.permission_id = rp.permission_id").
+				Where("t_user_roles.user_type = ? AND t_user_roles.user_id = ?", userType, id).
+				Pluck("p.permission", &result).Error
+			return result, err
+		},
+	})
+	if err != nil {
+		di.Logger().Error(err.Error())
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// Has 是否拥有全部权限
+func (p permission) Has(ctx context.Context, userType string, id int64, required ...string) (bool, error) {
+	granted, err := p.List(ctx, userType, id)
+	if err != nil {
+		return false, err
+	}
+	for _, perm := range required {
+		if !matchPermission(granted, perm) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// HasAny 是否拥有任一权限
+func (p permission) HasAny(ctx context.Context, userType string, id int64, required ...string) (bool, error) {
+	granted, err := p.List(ctx, userType, id)
+	if err != nil {
+		return false, err
+	}
+	for _, perm := range required {
+		if matchPermission(granted, perm) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ClearCache 清除用户权限缓存
+//
+//	用户角色变更后调用.
+func (permission) ClearCache(ctx context.Context, userType string, ids ...int64) error {
+	for _, id := range ids {
+		if err := di.Cache().Delete(ctx, fmt.Sprintf(consts.Permissions, userType, id)); err != nil {
+			di.Logger().Error(err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClearRoleCache 清除角色下全部用户的权限缓存
+//
+//	角色权限变更后调用.
+func (p permission) ClearRoleCache(ctx context.Context, roleID int64) error {
+	var userRoles []model.TUserRoles
+	if err := di.DemoDB().WithContext(ctx).Where("role_id = ?", roleID).Find(&userRoles).Error; err != nil {
+		di.Logger().Error(err.Error())
+		return err
+	}
+	for _, userRole := range userRoles {
+		if err := p.ClearCache(ctx, userRole.UserType, userRole.UserID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchPermission 权限匹配
+//
+//   - 匹配全部权限, user:* 匹配 user 资源全部权限.
+func matchPermission(granted []string, required string) bool {
+	for _, perm := range granted {
+		if perm == "*" || perm == required {
+			return true
+		}
+		if strings.HasSuffix(perm, ":*") && strings.HasPrefix(required, perm[:len(perm)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go-demo/config/di"
+	"go-demo/internal/consts"
+	"go-demo/pkg/logx"
+
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+type responseCache struct{}
+
+var ResponseCache responseCache
+
+// Versions 标签当前版本, 未失效过的标签为 0
+//
+//	版本参与响应缓存键, 失效后旧缓存不再命中(包括各实例的本地缓存), 到期后自然清除.
+//	版本在本地缓存 response_cache_tag_ttl 秒, 仅本地未命中的标签访问 Redis.
+func (responseCache) Versions(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	local := di.ResponseCacheTags()
+	versions := make([]string, len(tags))
+	keys := make([]string, 0, len(tags))
+	missing := make([]int, 0, len(tags)) // 本地未命中的标签下标
+	for i, tag := range tags {
+		key := fmt.Sprintf(consts.ResponseCacheTag, tag)
+		if local != nil {
+			if version, ok := local.Get(key); ok {
+				versions[i] = string(version)
+				continue
+			}
+		}
+		keys = append(keys, key)
+		missing = append(missing, i)
+	}
+	if len(keys) == 0 {
+		return versions, nil
+	}
+
+	values, err := di.CacheRedis().MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for j, value := range values {
+		version := "0"
+		if value != nil {
+			version = cast.ToString(value)
+		}
+		versions[missing[j]] = version
+		if local != nil {
+			local.Set(keys[j], []byte(version))
+		}
+	}
+
+	return versions, nil
+}
+
+// Invalidate 使标签关联的响应缓存失效
+//
+//	数据变更后调用, 标签见 consts.CacheTag*. 当前实例立即生效, 其他实例在标签版本本地缓存过期后生效.
+//	标签版本 consts.ResponseCacheTagTTL 后过期, 避免 Redis 中标签只增不减.
+func (responseCache) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	local := di.ResponseCacheTags()
+	pipe := di.CacheRedis().Pipeline()
+	for _, tag := range tags {
+		key := fmt.Sprintf(consts.ResponseCacheTag, tag)
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, consts.ResponseCacheTagTTL)
+		if local != nil {
+			local.Del(key)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logx.L(ctx).Error(err.Error(), zap.Strings("tags", tags))
+		return err
+	}
+
+	return nil
+}
+
+// Touch 延长标签版本的过期时间
+//
+//	生成响应缓存后调用, 保证标签版本晚于基于该版本的缓存过期, 版本重新计数时不会命中旧缓存. 未失效过的标签不处理.
+func (responseCache) Touch(ctx context.Context, tags []string, versions []string) {
+	pipe := di.CacheRedis().Pipeline()
+	for i, tag := range tags {
+		if versions[i] != "0" {
+			pipe.Expire(ctx, fmt.Sprintf(consts.ResponseCacheTag, tag), consts.ResponseCacheTagTTL)
+		}
+	}
+	if pipe.Len() == 0 {
+		return
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logx.L(ctx).Warn(err.Error())
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"go-demo/config/di"
 	"go-demo/internal/types"
+	"go-demo/pkg/i18nx"
+	"go-demo/pkg/metricx"
 
 	"github.com/goccy/go-json"
 	"github.com/gorilla/websocket"
@@ -40,10 +42,25 @@ func (ws) Send(client *types.WSClient, msgType string, msgData map[string]any) e
 		di.Logger().Error(err.Error())
 		return err
 	}
+	metricx.WSMessage("out", msgType)
 
 	return nil
 }
 
+// SendError 发送错误消息
+//
+//	message 为默认语言信息, 客户端语言的消息目录中有 code 对应的消息时使用该消息.
+func (ws) SendError(client *types.WSClient, msgType, code, message string) error {
+	if localized, ok := i18nx.Lookup(client.Locale, code); ok {
+		message = localized
+	}
+
+	return WS.Send(client, msgType, map[string]any{
+		"code":    code,
+		"message": message,
+	})
+}
+
 // Close 关闭 client
 func (ws) Close(client *types.WSClient) {
 	if client.IsClosed {
@@ -37,7 +37,7 @@ func (user) AddUser(ctx context.Context, t *asynq.Task) error {
 	if err := gox.CopyViaJSON(user, &userData); err != nil {
 		return err
 	}
-	if err := di.DemoDB().Model(&model.TUsers{}).Create(userData).Error; err != nil {
+	if err := di.DemoDB().WithContext(ctx).Model(&model.TUsers{}).Create(userData).Error; err != nil {
 		return err
 	}
 
@@ -8,6 +8,7 @@ import "github.com/gorilla/websocket"
 //	这个对象用于存放客户端的 ws 连接信息和用户信息
 type WSClient struct {
 	UserID   int64
+	Locale   string // 客户端语言, 见 i18nx
 	Conn     *websocket.Conn
 	IsClosed bool
 }
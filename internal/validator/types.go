@@ -0,0 +1,28 @@
+// Package validator 自定义参数类型
+//
+//	项目中通用的参数类型在这里注册, 注册后即可在 ginx 参数模式中使用.
+package validator
+
+import (
+	"go-demo/pkg/ginx"
+)
+
+// RegisterTypes 注册自定义参数类型
+func RegisterTypes() {
+	// 手机号, 中国大陆11位手机号
+	ginx.RegisterType("mobile", func(paramName string, paramValue any, allowEmpty bool) (any, error) {
+		return ginx.Filter(paramName, paramValue, `regex(^1[3-9]\d{9}$)`, allowEmpty)
+	})
+
+	// 订单号, 日期+10位数字, 比如 202401011234567890
+	ginx.RegisterType("order_no", func(paramName string, paramValue any, allowEmpty bool) (any, error) {
+		value, err := ginx.Filter(paramName, paramValue, `regex(^\d{18}$)`, allowEmpty)
+		if err != nil || value.(string) == "" {
+			return value, err
+		}
+		if _, err := ginx.Filter(paramName, value.(string)[:4]+"-"+value.(string)[4:6]+"-"+value.(string)[6:8], "date", false); err != nil {
+			return nil, ginx.ErrParamInvalid
+		}
+		return value, nil
+	})
+}
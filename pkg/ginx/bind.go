@@ -0,0 +1,135 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go-demo/pkg/gox"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BindJSON 获取 JSON 参数并绑定到结构体
+//
+//	dst 为结构体指针, 字段 json tag 为参数键名, ginx tag 格式 "paramName:paramType:paramPattern", 含义同 GetJSONBody() patterns.
+//	没有 ginx tag 的字段会被忽略. 选传参数未传值时字段保持原值, 需要区分是否传值时字段可定义为指针类型.
+//	结构体及结构体切片类型的字段, 其子字段的 ginx tag 会作为嵌套参数校验, 字段自身类型使用 object/[]object.
+//
+//	例如:
+//		var req struct {
+//			UserName string `json:"user_name" ginx:"用户名:string:+"`
+//			IsVip    *int64 `json:"is_vip" ginx:"VIP身份:[0,1]:?"`
+//			Items    []struct {
+//				SKU string `json:"sku" ginx:"商品编码:string:+"`
+//				Qty int64  `json:"qty" ginx:"数量:+integer:+"`
+//			} `json:"items" ginx:"商品:[]object{1,}:+"`
+//		}
+func BindJSON(c *gin.Context, dst any) error {
+	patterns, err := structPatterns(c, dst, true)
+	if err != nil {
+		return err
+	}
+	jsonBody, err := GetJSONBody(c, patterns)
+	if err != nil {
+		return err
+	}
+
+	return bindResult(c, jsonBody, dst)
+}
+
+// BindQuery 获取 Query 参数并绑定到结构体
+//
+//	dst 为结构体指针, 字段 json tag 为参数键名, ginx tag 格式 "paramName:paramType:defaultValue", 含义同 GetQueries() patterns.
+//	没有 ginx tag 的字段会被忽略.
+//
+//	例如:
+//		var req struct {
+//			Page     int64  `json:"page" ginx:"页码:+integer:1"`
+//			UserName string `json:"user_name" ginx:"用户名:string:\"\""`
+//		}
+func BindQuery(c *gin.Context, dst any) error {
+	patterns, err := structPatterns(c, dst, false)
+	if err != nil {
+		return err
+	}
+	queries, err := GetQueries(c, patterns)
+	if err != nil {
+		return err
+	}
+
+	return bindResult(c, queries, dst)
+}
+
+// structPatterns 由结构体 tag 生成参数模式
+//
+//	nested 为 true 时, 结构体及结构体切片类型字段会递归生成子字段模式, 见 GetJSONBody() 点号路径.
+func structPatterns(c *gin.Context, dst any, nested bool) ([]string, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		InternalError(c, errors.New("参数绑定目标必须为结构体指针"))
+		return nil, ErrParamBind
+	}
+
+	patterns, err := fieldPatterns(rv.Elem().Type(), "", nested)
+	if err != nil {
+		InternalError(c, err)
+		return nil, ErrParamBind
+	}
+
+	return patterns, nil
+}
+
+// fieldPatterns 由结构体字段生成参数模式, prefix 为键名前缀
+func fieldPatterns(rt reflect.Type, prefix string, nested bool) ([]string, error) {
+	patterns := make([]string, 0)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("ginx")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			return nil, errors.New("参数绑定字段缺少 json tag: " + field.Name)
+		}
+		patterns = append(patterns, prefix+key+":"+tag)
+		if !nested {
+			continue
+		}
+		// 嵌套结构体
+		ft := field.Type
+		childPrefix := prefix + key + "."
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+			childPrefix = prefix + key + "[]."
+		}
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		children, err := fieldPatterns(ft, childPrefix, nested)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, children...)
+	}
+
+	return patterns, nil
+}
+
+// bindResult 校验结果写入结构体
+func bindResult(c *gin.Context, result map[string]any, dst any) error {
+	if err := gox.CopyViaJSON(result, dst); err != nil { // 字段类型与参数类型不匹配属于开发错误
+		InternalError(c, fmt.Errorf("参数绑定到 %T 失败: %w", dst, err))
+		return ErrParamBind
+	}
+
+	return nil
+}
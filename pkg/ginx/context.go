@@ -0,0 +1,16 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DB 绑定请求 context 的 *gorm.DB
+//
+//	请求超时(Timeout 中间件)或客户端断开时请求 context 取消, 查询随之中止并释放连接. Redis 操作同样应传入 c.Request.Context().
+func DB(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(c.Request.Context())
+}
@@ -0,0 +1,112 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"time"
+
+	"go-demo/pkg/gox"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/cache/v9"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// CountMode 分页计数策略
+type CountMode string
+
+const (
+	CountExact     CountMode = ""          // 精确计数, 默认
+	CountCached    CountMode = "cached"    // 缓存计数, 按查询语句缓存精确计数结果, 需设置 PageQuery.CountCache
+	CountEstimated CountMode = "estimated" // 估算计数, 无查询条件时取 information_schema 表行数, 否则取 EXPLAIN 预估行数
+	CountHasMore   CountMode = "has_more"  // 不计数, 多取一条记录判断是否还有数据, 不输出总页数与总记录数
+)
+
+const defaultCountTTL = time.Minute // 缓存计数默认时长
+
+// countResults 按计数策略计算总记录数
+func countResults(c *gin.Context, tx *gorm.DB, pageQuery PageQuery) (int64, error) {
+	switch pageQuery.Count {
+	case CountCached:
+		return cachedCount(c, tx, pageQuery)
+	case CountEstimated:
+		return estimatedCount(tx)
+	default:
+		var totalResults int64
+		err := tx.Count(&totalResults).Error
+		return totalResults, err
+	}
+}
+
+// cachedCount 缓存计数
+//
+//	缓存键为计数语句(含绑定参数)的 MD5, 相同查询共享缓存, 缓存未命中时并发请求只计数一次.
+func cachedCount(c *gin.Context, tx *gorm.DB, pageQuery PageQuery) (int64, error) {
+	if pageQuery.CountCache == nil {
+		var totalResults int64
+		err := tx.Count(&totalResults).Error
+		return totalResults, err
+	}
+	var n int64
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Count(&n).Statement
+	key := "ginx:count:" + gox.MD5(tx.Dialector.Explain(stmt.SQL.String(), stmt.Vars...))
+	ttl := pageQuery.CountTTL
+	if ttl <= 0 {
+		ttl = defaultCountTTL
+	}
+
+	var totalResults int64
+	err := pageQuery.CountCache.Once(&cache.Item{
+		Ctx:   c.Request.Context(),
+		Key:   key,
+		Value: &totalResults,
+		TTL:   ttl,
+		Do: func(*cache.Item) (any, error) {
+			var count int64
+			err := tx.Count(&count).Error
+			return count, err
+		},
+	})
+
+	return totalResults, err
+}
+
+// estimatedCount 估算计数
+//
+//	单表无查询条件时取 information_schema.TABLES.TABLE_ROWS, 否则取 EXPLAIN 首行 rows*filtered/100. 仅支持 MySQL.
+func estimatedCount(tx *gorm.DB) (int64, error) {
+	var n int64
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Count(&n).Statement
+	_, hasWhere := stmt.Clauses["WHERE"]
+	if !hasWhere && len(stmt.Joins) == 0 && stmt.Table != "" {
+		var tableRows *int64
+		err := tx.Session(&gorm.Session{NewDB: true}).
+			Raw("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", stmt.Table).
+			Scan(&tableRows).Error
+		if err != nil {
+			return 0, err
+		}
+		if tableRows != nil {
+			return *tableRows, nil
+		}
+	}
+
+	stmt = tx.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]any{}).Statement
+	plans := make([]map[string]any, 0)
+	err := tx.Session(&gorm.Session{NewDB: true}).Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Scan(&plans).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, nil
+	}
+	rows := cast.ToFloat64(plans[0]["rows"])
+	filtered := 100.0
+	if value, ok := plans[0]["filtered"]; ok && value != nil {
+		filtered = cast.ToFloat64(value)
+	}
+
+	return int64(rows * filtered / 100), nil
+}
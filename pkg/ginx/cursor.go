@@ -0,0 +1,336 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+var orderByRegexp = regexp.MustCompile(`(?i)^([\w.]+)(\s+(ASC|DESC))?$`) // 游标分页排序字段
+
+const cursorTimeKey = "t" // 游标中时间值的键名
+
+// CursorPaging 游标分页结果
+type CursorPaging struct {
+	PerPage    int64  `json:"per_page"`    // 页大小
+	NextCursor string `json:"next_cursor"` // 下一页游标, 空表示没有下一页
+	PrevCursor string `json:"prev_cursor"` // 上一页游标, 空表示没有上一页
+}
+
+// cursor 游标内容
+type cursor struct {
+	Values   []any `json:"v"` // 边界记录的排序字段值
+	Backward bool  `json:"b"` // 是否向前翻页
+}
+
+// orderKey 排序字段
+type orderKey struct {
+	column string // 查询中的字段, 可以带表名
+	name   string // 不带表名的字段名, 用于从结果中取值
+	desc   bool
+}
+
+// CursorPaginate 获取游标分页数据
+//
+//	适用于大表与无限滚动, 不计算总记录数, 不使用 OFFSET. 客户端参数 cursor 为上次结果中的 next_cursor/prev_cursor, 不传表示第一页.
+//	pageQuery.OrderBy 必填, 格式为逗号分隔的 "字段 [ASC|DESC]", 客户端排序见 clientQuery(), 排序字段组合必须唯一(通常以主键结尾), 比如 "created_at DESC, user_id DESC".
+//	排序字段须出现在 items 元素结构体中, 按 gorm column tag/json tag/字段名蛇形 匹配.
+func CursorPaginate(c *gin.Context, items any, pageQuery PageQuery) (*CursorPaging, error) {
+	queries, err := GetQueries(c, []string{`cursor:游标:string:""`, "per_page:页大小:+integer:12"})
+	if err != nil {
+		return nil, err
+	}
+	perPage := queries["per_page"].(int64)
+
+	tx, orderBy, err := buildQuery(c, pageQuery)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseOrderBy(orderBy)
+	if err != nil {
+		InternalError(c, err)
+		return nil, ErrInternal
+	}
+	tx = selectOrderKeys(c, tx, pageQuery, keys)
+	cur := &cursor{}
+	if cursorStr := queries["cursor"].(string); cursorStr != "" {
+		cur, err = decodeCursor(cursorStr, len(keys))
+		if err != nil {
+			return nil, renderParamError(c, invalidError("游标"))
+		}
+	}
+
+	// 查询, 多取一条用于判断是否还有数据
+	if len(cur.Values) > 0 {
+		where, bindParams := keysetWhere(keys, cur.Values, cur.Backward)
+		tx = tx.Where(where, bindParams...)
+	}
+	orders := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.desc != cur.Backward { // 向前翻页时反向排序, 取到结果后再反转
+			orders = append(orders, key.column+" DESC")
+		} else {
+			orders = append(orders, key.column+" ASC")
+		}
+	}
+	if err := tx.Order(strings.Join(orders, ", ")).Limit(int(perPage) + 1).Find(items).Error; err != nil {
+		InternalError(c, nil)
+		return nil, ErrInternal
+	}
+
+	// 结果
+	rv := reflect.ValueOf(items).Elem()
+	hasMore := int64(rv.Len()) > perPage
+	if hasMore {
+		rv.Set(rv.Slice(0, int(perPage)))
+	}
+	if cur.Backward {
+		reverseSlice(rv)
+	}
+	result := &CursorPaging{PerPage: perPage}
+	if rv.Len() == 0 {
+		return result, nil
+	}
+	// 向后翻页: 有更多则有下一页, 带游标则有上一页; 向前翻页: 有更多则有上一页, 必然有下一页
+	hasNext := hasMore
+	hasPrev := len(cur.Values) > 0
+	if cur.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		if result.NextCursor, err = encodeCursor(pageQuery.DB, rv.Index(rv.Len()-1), keys, false); err != nil {
+			InternalError(c, err)
+			return nil, ErrInternal
+		}
+	}
+	if hasPrev {
+		if result.PrevCursor, err = encodeCursor(pageQuery.DB, rv.Index(0), keys, true); err != nil {
+			InternalError(c, err)
+			return nil, ErrInternal
+		}
+	}
+
+	return result, nil
+}
+
+// parseOrderBy 解析排序字段
+func parseOrderBy(orderBy string) ([]orderKey, error) {
+	keys := make([]orderKey, 0)
+	for _, part := range strings.Split(orderBy, ",") {
+		matches := orderByRegexp.FindStringSubmatch(strings.TrimSpace(part))
+		if matches == nil {
+			return nil, errors.New("游标分页排序字段错误: " + orderBy)
+		}
+		name := matches[1]
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+			name = name[idx+1:]
+		}
+		keys = append(keys, orderKey{column: matches[1], name: name, desc: strings.EqualFold(matches[3], "DESC")})
+	}
+
+	return keys, nil
+}
+
+// selectOrderKeys 客户端选择了返回字段时补充排序字段与主键, 以便从结果中取游标值
+//
+//	输出时仍按客户端选择的字段裁剪, 见 selectFields().
+func selectOrderKeys(c *gin.Context, tx *gorm.DB, pageQuery PageQuery, keys []orderKey) *gorm.DB {
+	value, ok := c.Get(fieldsKey)
+	if !ok {
+		return tx
+	}
+	fields := value.([]string)
+	columns := slices.Clone(fields)
+	for _, key := range keys {
+		if !slices.Contains(fields, key.name) {
+			columns = append(columns, key.column)
+		}
+	}
+	if table, pk := primaryKey(pageQuery); pk != "" && !slices.Contains(fields, pk) && !slices.ContainsFunc(keys, func(key orderKey) bool { return key.name == pk }) {
+		columns = append(columns, table+"."+pk) // 带表名, 避免 Joins 时字段不明确
+	}
+
+	return tx.Select(columns)
+}
+
+// primaryKey pageQuery.Model 的表名与主键, 没有时为空
+func primaryKey(pageQuery PageQuery) (string, string) {
+	if pageQuery.Model == nil {
+		return "", ""
+	}
+	stmt := &gorm.Statement{DB: pageQuery.DB}
+	if err := stmt.Parse(pageQuery.Model); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return "", ""
+	}
+
+	return stmt.Schema.Table, stmt.Schema.PrioritizedPrimaryField.DBName
+}
+
+// withPrimaryKey 排序字段不含主键时以主键补充排序, 保证排序字段组合唯一
+func withPrimaryKey(pageQuery PageQuery, keys []orderKey) ([]orderKey, error) {
+	table, pk := primaryKey(pageQuery)
+	if pk == "" {
+		return nil, errors.New("没有主键")
+	}
+	if slices.ContainsFunc(keys, func(key orderKey) bool { return key.name == pk }) {
+		return keys, nil
+	}
+
+	return append(keys, orderKey{column: table + "." + pk, name: pk, desc: keys[len(keys)-1].desc}), nil
+}
+
+// keysetWhere 生成游标条件
+//
+//	比如 created_at DESC, user_id DESC 向后翻页: created_at < ? OR (created_at = ? AND user_id < ?)
+func keysetWhere(keys []orderKey, values []any, backward bool) (string, []any) {
+	ors := make([]string, 0, len(keys))
+	bindParams := make([]any, 0)
+	for i, key := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, keys[j].column+" = ?")
+			bindParams = append(bindParams, values[j])
+		}
+		op := ">"
+		if key.desc != backward {
+			op = "<"
+		}
+		ands = append(ands, key.column+" "+op+" ?")
+		bindParams = append(bindParams, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return strings.Join(ors, " OR "), bindParams
+}
+
+// orderValues 取记录的排序字段值
+func orderValues(db *gorm.DB, item reflect.Value, keys []orderKey) ([]any, error) {
+	for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+		item = item.Elem()
+	}
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		value, ok := fieldByColumn(db, item, key.name)
+		if !ok {
+			return nil, errors.New("游标分页排序字段不在结果中: " + key.name)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// encodeCursor 由边界记录生成游标
+//
+//	时间值编码为 {"t": RFC3339Nano UTC}, 解析后还原为 time.Time 绑定, 与服务端及数据库时区无关.
+func encodeCursor(db *gorm.DB, item reflect.Value, keys []orderKey, backward bool) (string, error) {
+	values, err := orderValues(db, item, keys)
+	if err != nil {
+		return "", err
+	}
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			values[i] = map[string]string{cursorTimeKey: t.UTC().Format(time.RFC3339Nano)}
+		}
+	}
+	cursorBytes, err := json.Marshal(cursor{Values: values, Backward: backward})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursorBytes), nil
+}
+
+// decodeCursor 解析游标
+func decodeCursor(cursorStr string, keyCount int) (*cursor, error) {
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return nil, err
+	}
+	cur := &cursor{}
+	decoder := json.NewDecoder(bytes.NewReader(cursorBytes))
+	decoder.UseNumber() // 避免大整数精度丢失
+	if err := decoder.Decode(cur); err != nil {
+		return nil, err
+	}
+	if len(cur.Values) != keyCount {
+		return nil, fmt.Errorf("cursor values count %d != %d", len(cur.Values), keyCount)
+	}
+	for i, value := range cur.Values {
+		switch v := value.(type) {
+		case json.Number:
+			cur.Values[i] = v.String()
+		case map[string]any: // 时间
+			timeStr, ok := v[cursorTimeKey].(string)
+			if !ok || len(v) != 1 {
+				return nil, fmt.Errorf("cursor value %v", v)
+			}
+			if cur.Values[i], err = time.Parse(time.RFC3339Nano, timeStr); err != nil {
+				return nil, err
+			}
+		case string, bool:
+		default:
+			return nil, fmt.Errorf("cursor value type %T", value)
+		}
+	}
+
+	return cur, nil
+}
+
+// fieldByColumn 按字段名从结构体中取值
+func fieldByColumn(db *gorm.DB, item reflect.Value, column string) (any, bool) {
+	if item.Kind() == reflect.Map {
+		value := item.MapIndex(reflect.ValueOf(column))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return value.Interface(), true
+	}
+	if item.Kind() != reflect.Struct {
+		return nil, false
+	}
+	rt := item.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		names := []string{db.NamingStrategy.ColumnName("", field.Name)}
+		for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(setting), "column:"); ok {
+				names = append(names, name)
+			}
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if name == column {
+				return item.Field(i).Interface(), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// reverseSlice 反转切片
+func reverseSlice(rv reflect.Value) {
+	swap := reflect.Swapper(rv.Interface())
+	for i, j := 0, rv.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
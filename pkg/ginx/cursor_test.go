@@ -0,0 +1,118 @@
+package ginx
+
+import (
+	"encoding/base64"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	type user struct {
+		UserID    int64     `json:"user_id"`
+		Name      string    `gorm:"column:user_name"`
+		IsVip     bool      `json:"is_vip"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+	loc := time.FixedZone("UTC+8", 8*3600)
+	item := user{
+		UserID:    9007199254740993, // 超过 float64 精度
+		Name:      "张三",
+		IsVip:     true,
+		CreatedAt: time.Date(2024, 1, 2, 8, 4, 5, 123456789, loc),
+	}
+	keys, err := parseOrderBy("users.created_at DESC, user_name, is_vip, user_id DESC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backward := range []bool{false, true} {
+		cursorStr, err := encodeCursor(db, reflect.ValueOf(&item), keys, backward)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cur, err := decodeCursor(cursorStr, len(keys))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cur.Backward != backward {
+			t.Errorf("backward = %v, want %v", cur.Backward, backward)
+		}
+		createdAt, ok := cur.Values[0].(time.Time)
+		if !ok || !createdAt.Equal(item.CreatedAt) || createdAt.Location() != time.UTC {
+			t.Errorf("created_at = %#v, want %v in UTC", cur.Values[0], item.CreatedAt)
+		}
+		want := []any{"张三", true, "9007199254740993"}
+		if !reflect.DeepEqual(cur.Values[1:], want) {
+			t.Errorf("values = %#v, want %#v", cur.Values[1:], want)
+		}
+	}
+
+	if _, err := encodeCursor(db, reflect.ValueOf(item), []orderKey{{column: "age", name: "age"}}, false); err == nil {
+		t.Error("encodeCursor with missing field: want error")
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"v":[1],"b":false}`,              // 数量不符
+		`{"v":[[1],2],"b":false}`,          // 数组值
+		`{"v":[{"t":"2024"},2],"b":false}`, // 时间格式错误
+		`{"v":[{"x":"1"},2],"b":false}`,    // 未知对象
+	}
+	for _, content := range tests {
+		if cur, err := decodeCursor(base64.RawURLEncoding.EncodeToString([]byte(content)), 2); err == nil {
+			t.Errorf("decodeCursor(%s) = %#v, want error", content, cur)
+		}
+	}
+	if _, err := decodeCursor("not base64!", 2); err == nil {
+		t.Error("decodeCursor(not base64!): want error")
+	}
+}
+
+func TestKeysetWhere(t *testing.T) {
+	tests := []struct {
+		orderBy    string
+		values     []any
+		backward   bool
+		where      string
+		bindParams []any
+	}{
+		{
+			"user_id DESC", []any{"10"}, false,
+			"(user_id < ?)", []any{"10"},
+		},
+		{
+			"user_id DESC", []any{"10"}, true,
+			"(user_id > ?)", []any{"10"},
+		},
+		{
+			"created_at DESC, user_id DESC", []any{"t", "10"}, false,
+			"(created_at < ?) OR (created_at = ? AND user_id < ?)", []any{"t", "t", "10"},
+		},
+		{
+			"users.created_at, users.user_id DESC", []any{"t", "10"}, false,
+			"(users.created_at > ?) OR (users.created_at = ? AND users.user_id < ?)", []any{"t", "t", "10"},
+		},
+		{
+			"a ASC, b DESC, c", []any{1, 2, 3}, true,
+			"(a < ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c < ?)", []any{1, 1, 2, 1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		keys, err := parseOrderBy(tt.orderBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		where, bindParams := keysetWhere(keys, tt.values, tt.backward)
+		if where != tt.where || !slices.Equal(bindParams, tt.bindParams) {
+			t.Errorf("keysetWhere(%q, %v, %v) = %q, %v, want %q, %v", tt.orderBy, tt.values, tt.backward, where, bindParams, tt.where, tt.bindParams)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go-demo/pkg/i18nx"
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// AppError 应用错误
+//
+//	由 RegisterCode() 注册错误码得到, 使用 Wrap()/WithArgs() 派生携带原因与消息参数的错误, 派生错误与注册错误 errors.Is 相等.
+type AppError struct {
+	HTTPCode int    // HTTP 状态码
+	Code     string // 错误码
+	MsgKey   string // 消息 key, 为空时使用 Code
+	Message  string // 默认语言信息, 消息目录中没有 MsgKey 时使用
+	Args     []any  // 消息参数, 不为空时消息作为 fmt.Sprintf 格式模板
+	Cause    error  // 原因
+}
+
+func (e *AppError) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Cause.Error()
+	}
+	return e.Code
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码相同即相等
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 派生携带原因的错误
+func (e *AppError) Wrap(cause error) *AppError {
+	err := *e
+	err.Cause = cause
+	return &err
+}
+
+// WithArgs 派生携带消息参数的错误
+func (e *AppError) WithArgs(args ...any) *AppError {
+	err := *e
+	err.Args = args
+	return &err
+}
+
+// message 本地化错误信息
+func (e *AppError) message(locale string) string {
+	key := e.MsgKey
+	if key == "" {
+		key = e.Code
+	}
+	message, ok := i18nx.Lookup(locale, key)
+	if !ok {
+		message = e.Message
+	}
+	if message == "" {
+		message = i18nx.T(locale, key)
+	}
+	if len(e.Args) > 0 {
+		return fmt.Sprintf(message, e.Args...)
+	}
+
+	return message
+}
+
+var (
+	codes   = map[string]*AppError{} // 错误码注册表
+	codesMu sync.RWMutex
+)
+
+// RegisterCode 注册错误码
+//
+//	message 为默认语言信息, 其他语言在消息目录中以 code 为 key 配置. 错误码重复会 panic, 应在包级变量中注册, 比如:
+//		var ErrUserNotFound = ginx.RegisterCode(404, "UserNotFound", "用户不存在")
+func RegisterCode(httpCode int, code, message string) *AppError {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	if _, ok := codes[code]; ok {
+		panic("ginx: 错误码重复: " + code)
+	}
+	err := &AppError{HTTPCode: httpCode, Code: code, Message: message}
+	codes[code] = err
+
+	return err
+}
+
+// LookupCode 查找已注册的错误码
+func LookupCode(code string) (*AppError, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	err, ok := codes[code]
+	return err, ok
+}
+
+// Codes 全部已注册的错误码, 按错误码排序
+func Codes() []*AppError {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	result := make([]*AppError, 0, len(codes))
+	for _, err := range codes {
+		result = append(result, err)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+
+	return result
+}
+
+// codeError 由错误码获取错误, 未注册时按500处理
+func codeError(code string) *AppError {
+	if err, ok := LookupCode(code); ok {
+		return err
+	}
+	return &AppError{HTTPCode: 500, Code: code}
+}
+
+// 内置错误码
+var (
+	ErrInternal           = RegisterCode(500, "InternalError", "服务异常, 请稍后重试")
+	ErrNotFound           = RegisterCode(404, "ResourceNotFound", "您请求的资源不存在")
+	ErrConflict           = RegisterCode(409, "ResourceConflict", "资源已存在")
+	ErrTimeout            = RegisterCode(408, "RequestTimeout", "请求超时, 请稍后重试")
+	ErrCanceled           = RegisterCode(499, "RequestCanceled", "请求已取消") // 客户端断开, 响应不会被读取
+	ErrParamEmpty         = RegisterCode(400, "ParamEmpty", "参数不能为空")     // 参数为空
+	ErrParamInvalid       = RegisterCode(400, "ParamInvalid", "参数不正确")    // 参数不正确
+	ErrParamPattern       = RegisterCode(500, "ParamPatternError", "服务异常, 请稍后重试")
+	ErrParamType          = RegisterCode(500, "ParamTypeError", "服务异常, 请稍后重试")
+	ErrParamTypeUndefined = RegisterCode(500, "ParamTypeUndefined", "服务异常, 请稍后重试")
+	ErrParamBind          = RegisterCode(500, "ParamBindError", "服务异常, 请稍后重试")
+)
+
+// Fail 输出错误
+//
+//	*AppError 按其错误码输出; GORM 记录不存在与 Redis 键不存在输出 ResourceNotFound, 唯一键冲突输出 ResourceConflict,
+//	超时输出 RequestTimeout, 客户端断开输出 RequestCanceled, 其他错误输出 InternalError. 500 错误会记录日志.
+//	已输出响应时不再输出, 所以 ginx 函数返回的 error 也可以直接传入.
+func Fail(c *gin.Context, err error) {
+	if err == nil || responded(c) {
+		return
+	}
+
+	var appErr *AppError
+	var mysqlErr *mysql.MySQLError
+	switch {
+	case errors.As(err, &appErr):
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, redis.Nil):
+		appErr = ErrNotFound.Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.As(err, &mysqlErr) && mysqlErr.Number == 1062:
+		appErr = ErrConflict.Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		appErr = ErrTimeout.Wrap(err)
+	case errors.Is(err, context.Canceled):
+		appErr = ErrCanceled.Wrap(err)
+	default:
+		appErr = ErrInternal.Wrap(err)
+	}
+	if appErr.HTTPCode >= 500 {
+		logx.L(c.Request.Context()).Error(err.Error())
+	}
+
+	renderError(c, appErr.HTTPCode, appErr.Code, appErr.message(Locale(c)), nil)
+}
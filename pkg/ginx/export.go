@@ -0,0 +1,246 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-demo/pkg/gox"
+	"go-demo/pkg/i18nx"
+	"go-demo/pkg/logx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+const (
+	exportBatchSize    = 1000   // 导出每批查询记录数
+	defaultExportLimit = 100000 // 导出默认最大记录数
+)
+
+// ErrExported 已输出导出文件
+//
+//	Paginate() 处于导出模式时返回此错误, 调用时与其他错误一样直接结束业务逻辑即可.
+var ErrExported = errors.New("Exported")
+
+// ExportColumn 导出列
+type ExportColumn struct {
+	Key   string // 列表元素的 json 键名
+	Label string // 表头, 会按请求 locale 翻译
+}
+
+// rowWriter 导出文件逐行写入
+type rowWriter interface {
+	WriteRow(row []string) error
+	Flush() error
+	Close() error
+}
+
+// csvWriter CSV 逐行写入
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvWriter) WriteRow(row []string) error {
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+// exportFormats 导出格式
+var exportFormats = map[string]struct {
+	contentType string
+	newWriter   func(w io.Writer) (rowWriter, error)
+}{
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		newWriter: func(w io.Writer) (rowWriter, error) {
+			if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil { // 写入 UTF-8 BOM, 以便 Excel 正确识别编码
+				return nil, err
+			}
+			return &csvWriter{w: csv.NewWriter(w)}, nil
+		},
+	},
+	"xlsx": {
+		contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		newWriter: func(w io.Writer) (rowWriter, error) {
+			return gox.NewXLSXWriter(w)
+		},
+	},
+}
+
+// exportList 导出列表
+//
+//	客户端参数 export=csv|xlsx, 忽略 page/per_page, 客户端排序/筛选/字段选择同样生效.
+//	按批查询并直接写入响应, 最多导出 pageQuery.ExportLimit 条记录. 开始输出后出现错误只能记录日志并中断响应.
+//	有 pageQuery.Model 时按排序字段+主键游标(keyset)分批查询, 否则按 OFFSET 分批. 需跳过超时控制, 否则响应会被缓冲, 见 middleware.Timeout().
+func exportList(c *gin.Context, items any, pageQuery PageQuery) error {
+	queries, err := GetQueries(c, []string{`export:导出格式:["csv","xlsx"]:required`})
+	if err != nil {
+		return err
+	}
+	format := exportFormats[queries["export"].(string)]
+
+	tx, orderBy, err := buildQuery(c, pageQuery)
+	if err != nil {
+		return err
+	}
+	keys, keysetErr := parseOrderBy(orderBy) // 没有排序或主键时按 OFFSET 分批
+	if keysetErr == nil {
+		keys, keysetErr = withPrimaryKey(pageQuery, keys)
+	}
+	if keysetErr == nil {
+		orders := lo.Map(keys, func(key orderKey, _ int) string {
+			return key.column + lo.Ternary(key.desc, " DESC", " ASC")
+		})
+		tx = selectOrderKeys(c, tx, pageQuery, keys).Order(strings.Join(orders, ", "))
+	} else {
+		tx = selectClientFields(c, tx)
+		if orderBy != "" {
+			tx = tx.Order(orderBy)
+		}
+	}
+	limit := pageQuery.ExportLimit
+	if limit <= 0 {
+		limit = defaultExportLimit
+	}
+	columns := pageQuery.Export
+	if value, ok := c.Get(fieldsKey); ok { // 客户端选择了返回字段
+		fields := value.([]string)
+		columns = lo.Filter(columns, func(column ExportColumn, _ int) bool {
+			return lo.Contains(fields, column.Key)
+		})
+	}
+
+	var w rowWriter
+	var after []any // 上一批最后一条记录的排序字段值
+	for offset := int64(0); offset < limit; offset += exportBatchSize {
+		size := min(exportBatchSize, limit-offset)
+		batchTx := tx.Offset(int(offset))
+		if keysetErr == nil {
+			batchTx = tx
+			if after != nil {
+				where, bindParams := keysetWhere(keys, after, false)
+				batchTx = tx.Where(where, bindParams...)
+			}
+		}
+		rows, last, err := exportBatch(batchTx, items, size)
+		if err == nil && keysetErr == nil && len(rows) > 0 {
+			after, err = orderValues(pageQuery.DB, last, keys)
+		}
+		if err != nil {
+			if w == nil { // 尚未输出, 可以返回错误响应
+				InternalError(c, err)
+				return ErrInternal
+			}
+			logx.L(c.Request.Context()).Error(err.Error())
+			break
+		}
+
+		// 首批查询成功后输出响应头与表头
+		if w == nil {
+			name := pageQuery.ExportName
+			if name == "" {
+				name = "export"
+			}
+			filename := name + "_" + time.Now().Format("20060102150405") + "." + queries["export"].(string)
+			c.Header("Content-Type", format.contentType)
+			c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+			c.Status(200)
+			markResponded(c)
+			if w, err = format.newWriter(c.Writer); err != nil {
+				logx.L(c.Request.Context()).Error(err.Error())
+				return ErrExported
+			}
+			locale := Locale(c)
+			header := lo.Map(columns, func(column ExportColumn, _ int) string {
+				return i18nx.T(locale, column.Label)
+			})
+			if err := w.WriteRow(header); err != nil {
+				logx.L(c.Request.Context()).Error(err.Error())
+				return ErrExported
+			}
+		}
+
+		for _, row := range rows {
+			record := lo.Map(columns, func(column ExportColumn, _ int) string {
+				return exportCell(row[column.Key])
+			})
+			if err := w.WriteRow(record); err != nil { // 通常为客户端断开连接
+				logx.L(c.Request.Context()).Error(err.Error())
+				return ErrExported
+			}
+		}
+		if err := w.Flush(); err != nil {
+			logx.L(c.Request.Context()).Error(err.Error())
+			return ErrExported
+		}
+		c.Writer.Flush()
+		if int64(len(rows)) < size {
+			break
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		logx.L(c.Request.Context()).Error(err.Error())
+	}
+
+	return ErrExported
+}
+
+// exportBatch 查询一批记录
+//
+//	结果按 json 键名转换为 map, 数字保留原始精度. 同时返回最后一条原始记录, 用于取游标值.
+func exportBatch(tx *gorm.DB, items any, size int64) ([]map[string]any, reflect.Value, error) {
+	batch := reflect.New(reflect.TypeOf(items).Elem())
+	if err := tx.Limit(int(size)).Find(batch.Interface()).Error; err != nil {
+		return nil, reflect.Value{}, err
+	}
+	interim, err := json.Marshal(batch.Interface())
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	rows := make([]map[string]any, 0)
+	decoder := json.NewDecoder(bytes.NewReader(interim))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	var last reflect.Value
+	if n := batch.Elem().Len(); n > 0 {
+		last = batch.Elem().Index(n - 1)
+	}
+
+	return rows, last, nil
+}
+
+// exportCell 单元格文本
+func exportCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case map[string]any, []any:
+		cell, _ := json.Marshal(v)
+		return string(cell)
+	default:
+		return cast.ToString(v)
+	}
+}
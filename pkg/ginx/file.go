@@ -0,0 +1,136 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// File 上传文件
+type File struct {
+	*multipart.FileHeader
+	MIME string // 内容嗅探得到的 MIME 类型, 不采信客户端声明的 Content-Type
+	Ext  string // 小写扩展名, 含点号, 比如 .jpg
+}
+
+// Save 保存文件
+//
+//	dst 为目标文件路径, 目录不存在会创建, 文件存在会覆盖.
+func (f *File) Save(dst string) error {
+	src, err := f.Open()
+	if err != nil {
+		zap.L().Error(err.Error())
+		return err
+	}
+	defer func() {
+		if err := src.Close(); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		zap.L().Error(err.Error())
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		zap.L().Error(err.Error())
+		return err
+	}
+	defer func() {
+		if err := out.Close(); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}()
+
+	if _, err := io.Copy(out, src); err != nil {
+		zap.L().Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// sniffFile 嗅探文件内容类型
+func sniffFile(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	head := make([]byte, 512) // DetectContentType 最多读取前512字节
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return mediaType, nil
+}
+
+// checkFile 校验上传文件
+//
+//	allowed 为允许的扩展名与 MIME 类型, 点号开头的为扩展名, 其他为 MIME 类型, 支持 image/* 通配. 为空表示不限制.
+//	扩展名与 MIME 类型分别校验, 都声明时须同时满足.
+func checkFile(paramName string, paramValue any, allowed []string, allowEmpty bool) (*File, *paramError) {
+	fh, ok := paramValue.(*multipart.FileHeader)
+	if !ok {
+		return nil, invalidError(paramName)
+	}
+	if fh.Size == 0 && !allowEmpty {
+		return nil, emptyError(paramName)
+	}
+	mediaType, err := sniffFile(fh)
+	if err != nil {
+		return nil, &paramError{httpCode: 500, code: "ParamTypeError", err: err}
+	}
+	file := &File{
+		FileHeader: fh,
+		MIME:       mediaType,
+		Ext:        strings.ToLower(filepath.Ext(fh.Filename)),
+	}
+
+	exts := make([]string, 0)
+	mimes := make([]string, 0)
+	for _, item := range allowed {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if strings.HasPrefix(item, ".") {
+			exts = append(exts, item)
+		} else {
+			mimes = append(mimes, item)
+		}
+	}
+	if len(exts) > 0 && !lo.Contains(exts, file.Ext) {
+		return nil, &paramError{httpCode: 400, code: "ParamInvalid", msgKey: "param.file.type", args: []any{paramName}}
+	}
+	if len(mimes) > 0 && !lo.ContainsBy(mimes, func(m string) bool {
+		if prefix, ok := strings.CutSuffix(m, "/*"); ok {
+			return strings.HasPrefix(file.MIME, prefix+"/")
+		}
+		return m == file.MIME
+	}) {
+		return nil, &paramError{httpCode: 400, code: "ParamInvalid", msgKey: "param.file.type", args: []any{paramName}}
+	}
+
+	return file, nil
+}
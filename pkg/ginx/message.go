@@ -0,0 +1,74 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"go-demo/pkg/i18nx"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "ginx:locale" // 请求 locale Gin 上下文键名
+
+func init() { // 内置消息, 项目中可以通过 i18nx.Register()/i18nx.LoadFS() 覆盖或补充
+	i18nx.RegisterIfAbsent("zh", map[string]string{
+		"param.empty":          "%s不得为空",
+		"param.invalid":        "%s不正确",
+		"param.equal":          "%s须为%s",
+		"param.between":        "%s须在%s~%s之间",
+		"param.min":            "%s不得小于%s",
+		"param.max":            "%s不得大于%s",
+		"param.length.equal":   "%s长度须为%s",
+		"param.length.between": "%s长度须在%s~%s之间",
+		"param.length.min":     "%s长度不得小于%s",
+		"param.length.max":     "%s长度不得大于%s",
+		"param.size.equal":     "%s大小须为%s",
+		"param.size.between":   "%s大小须在%s~%s之间",
+		"param.size.min":       "%s大小不得小于%s",
+		"param.size.max":       "%s大小不得超过%s",
+		"param.file.type":      "%s文件类型不支持",
+	})
+	i18nx.RegisterIfAbsent("en", map[string]string{
+		"param.empty":          "%s is required",
+		"param.invalid":        "%s is invalid",
+		"param.equal":          "%s must be %s",
+		"param.between":        "%s must be between %s and %s",
+		"param.min":            "%s must be at least %s",
+		"param.max":            "%s must be at most %s",
+		"param.length.equal":   "%s must be %s characters/items long",
+		"param.length.between": "%s must be %s to %s characters/items long",
+		"param.length.min":     "%s must be at least %s characters/items long",
+		"param.length.max":     "%s must be at most %s characters/items long",
+		"param.size.equal":     "%s must be %s bytes",
+		"param.size.between":   "%s must be between %s and %s bytes",
+		"param.size.min":       "%s must be at least %s bytes",
+		"param.size.max":       "%s must not exceed %s bytes",
+		"param.file.type":      "%s file type is not supported",
+		"InternalError":        "Service error, please try again later",
+		"ResourceNotFound":     "The requested resource does not exist",
+		"ResourceConflict":     "The resource already exists",
+		"RequestTimeout":       "Request timed out, please try again later",
+		"RequestCanceled":      "Request canceled",
+		"页码":                   "Page",
+		"页大小":                  "Page size",
+		"游标":                   "Cursor",
+		"排序字段":                 "Sort field",
+		"筛选字段":                 "Filter field",
+		"返回字段":                 "Fields",
+		"导出格式":                 "Export format",
+	})
+}
+
+// Locale 获取请求 locale
+//
+//	根据请求头 Accept-Language 匹配已注册的消息目录, 未匹配到时为 i18nx.DefaultLocale. 结果缓存在 Gin 上下文中.
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	locale := i18nx.Match(c.GetHeader("Accept-Language"))
+	c.Set(localeKey, locale)
+
+	return locale
+}
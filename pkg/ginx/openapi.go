@@ -0,0 +1,615 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+)
+
+// Operation 接口文档
+//
+//	参数模式与 GetQueries()/GetJSONBody()/GetForm() 相同, 建议与控制器共用同一份模式定义.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Auth        bool        // 是否需要登录, 输出 Bearer 认证
+	Permissions []string    // 所需权限, 输出到 x-permissions 并补充到描述
+	Path        []string    // 路径参数模式 "paramKey:paramName:paramType", 未声明的路径参数为 string
+	Query       []string    // GetQueries() 参数模式
+	Header      []string    // 请求头参数模式 "headerKey:headerName:paramType", 均为可选
+	Body        []string    // GetJSONBody() 参数模式
+	Form        []string    // GetForm() 参数模式
+	QueryStruct any         // BindQuery() 结构体, 与 Query 合并
+	BodyStruct  any         // BindJSON() 结构体, 与 Body 合并
+	Status      int         // 成功状态码, 默认200
+	Response    any         // 成功响应数据, 比如结构体零值, 按 json tag 生成结构; Paging 时为列表元素
+	Paging      bool        // 是否 PageSuccess() 分页响应, 会补充 page/per_page 参数
+	Errors      []*AppError // 可能的错误, 参数错误与 InternalError 会自动补充
+}
+
+// OpenAPIInfo 文档信息
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string // 服务地址
+}
+
+var (
+	operations   = map[string]Operation{} // map[处理函数名]Operation
+	operationsMu sync.RWMutex
+
+	pathParamRegexp = regexp.MustCompile(`[:*](\w+)`)
+)
+
+// Doc 注册接口文档
+//
+//	handler 为路由的最后一个处理函数, 同一处理函数用于多个路由时共用文档. 应在程序启动时注册.
+//
+//	例如:
+//		ginx.Doc(controller.Account.GetUsersByID, ginx.Operation{
+//			Summary:  "用户详情",
+//			Path:     []string{"user_id:用户id:+integer"},
+//			Response: model.TUsers{},
+//			Errors:   []*ginx.AppError{consts.ErrUserNotFound},
+//		})
+func Doc(handler gin.HandlerFunc, op Operation) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	operations[handlerName(handler)] = op
+}
+
+// handlerName 处理函数名, 与 gin.RouteInfo.Handler 一致
+func handlerName(handler gin.HandlerFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+}
+
+// OpenAPI 生成 OpenAPI 3 文档
+//
+//	routes 为 gin.Engine.Routes(), 未注册文档的路由仅包含路径参数与通用错误.
+func OpenAPI(routes gin.RoutesInfo, info OpenAPIInfo) (map[string]any, error) {
+	operationsMu.RLock()
+	defer operationsMu.RUnlock()
+
+	paths := map[string]map[string]any{}
+	for _, route := range routes {
+		path := pathParamRegexp.ReplaceAllString(route.Path, "{$1}")
+		if _, ok := paths[path]; !ok {
+			paths[path] = map[string]any{}
+		}
+		op, documented := operations[route.Handler]
+		operation, err := op.build(route.Path)
+		if err != nil {
+			return nil, errors.New(route.Method + " " + route.Path + ": " + err.Error())
+		}
+		if documented {
+			name := strings.TrimSuffix(route.Handler, "-fm")
+			operation["operationId"] = name[strings.LastIndexByte(name, '.')+1:]
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	servers := lo.Map(info.Servers, func(url string, _ int) map[string]any {
+		return map[string]any{"url": url}
+	})
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Error": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"code":    map[string]any{"type": "string", "description": "错误码"},
+						"message": map[string]any{"type": "string", "description": "错误信息"},
+						"fields":  map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/FieldError"}, "description": "参数错误明细, 仅完整校验模式"},
+					},
+					"required": []string{"code", "message"},
+				},
+				"Problem": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"type":     map[string]any{"type": "string"},
+						"title":    map[string]any{"type": "string"},
+						"status":   map[string]any{"type": "integer"},
+						"detail":   map[string]any{"type": "string"},
+						"instance": map[string]any{"type": "string"},
+						"code":     map[string]any{"type": "string", "description": "错误码"},
+						"fields":   map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/FieldError"}, "description": "参数错误明细, 仅完整校验模式"},
+					},
+					"required": []string{"type", "title", "status", "detail", "code"},
+				},
+				"FieldError": valueSchema(reflect.TypeOf(FieldError{}), 0),
+			},
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+	if len(servers) > 0 {
+		doc["servers"] = servers
+	}
+
+	return doc, nil
+}
+
+// OpenAPIHandler 输出 OpenAPI 3 文档
+//
+//	首次请求时生成, 此时路由已全部注册.
+func OpenAPIHandler(r *gin.Engine, info OpenAPIInfo) gin.HandlerFunc {
+	var once sync.Once
+	var doc map[string]any
+	var err error
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc, err = OpenAPI(r.Routes(), info)
+		})
+		if err != nil {
+			InternalError(c, err)
+			return
+		}
+		Success(c, 200, doc)
+	}
+}
+
+// build 生成接口文档, path 为 Gin 路由路径
+func (op Operation) build(path string) (map[string]any, error) {
+	operation := map[string]any{}
+	if op.Summary != "" {
+		operation["summary"] = op.Summary
+	}
+	description := op.Description
+	if len(op.Permissions) > 0 {
+		operation["x-permissions"] = op.Permissions
+		description = strings.TrimSpace(description + "\n\n所需权限: " + strings.Join(op.Permissions, ", "))
+	}
+	if description != "" {
+		operation["description"] = description
+	}
+	if len(op.Tags) > 0 {
+		operation["tags"] = op.Tags
+	}
+	if op.Auth {
+		operation["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	// 路径参数
+	parameters := make([]map[string]any, 0)
+	declared := map[string][]string{}
+	for _, pattern := range op.Path {
+		atoms := strings.SplitN(pattern, ":", 3)
+		if len(atoms) != 3 {
+			return nil, errors.New("参数模式错误: " + pattern)
+		}
+		declared[atoms[0]] = atoms
+	}
+	for _, matches := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		schema := map[string]any{"type": "string"}
+		parameter := map[string]any{"name": matches[1], "in": "path", "required": true}
+		if atoms, ok := declared[matches[1]]; ok {
+			schema = typeSchema(atoms[2])
+			parameter["description"] = atoms[1]
+		}
+		parameter["schema"] = schema
+		parameters = append(parameters, parameter)
+	}
+
+	// Query 参数
+	queries := make([]string, 0)
+	if op.Paging {
+		queries = append(queries, "page:页码:+integer:1", "per_page:页大小:+integer:12")
+	}
+	queries = append(queries, op.Query...)
+	if op.QueryStruct != nil {
+		patterns, err := docStructPatterns(op.QueryStruct, false)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, patterns...)
+	}
+	for _, pattern := range queries {
+		atoms, ok := splitPattern(pattern)
+		if !ok {
+			return nil, errors.New("参数模式错误: " + pattern)
+		}
+		schema := typeSchema(atoms[2])
+		required := atoms[3] == "required"
+		if !required && atoms[3] != `""` && atoms[3] != "" {
+			if value, e := filterParam(atoms[1], atoms[3], atoms[2], false); e == nil { // 默认值按类型输出
+				schema["default"] = value
+			}
+		}
+		parameters = append(parameters, map[string]any{
+			"name":        atoms[0],
+			"in":          "query",
+			"description": atoms[1],
+			"required":    required,
+			"schema":      schema,
+		})
+	}
+
+	// 请求头参数
+	for _, pattern := range op.Header {
+		atoms := strings.SplitN(pattern, ":", 3)
+		if len(atoms) != 3 {
+			return nil, errors.New("参数模式错误: " + pattern)
+		}
+		parameters = append(parameters, map[string]any{
+			"name":        atoms[0],
+			"in":          "header",
+			"description": atoms[1],
+			"required":    false,
+			"schema":      typeSchema(atoms[2]),
+		})
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	// Body 参数
+	body := append([]string{}, op.Body...)
+	if op.BodyStruct != nil {
+		patterns, err := docStructPatterns(op.BodyStruct, true)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, patterns...)
+	}
+	if len(body) > 0 {
+		tree, err := buildParamTree(body)
+		if err != nil {
+			return nil, err
+		}
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": tree.schema()}},
+		}
+	} else if len(op.Form) > 0 {
+		tree := &paramNode{}
+		for _, pattern := range op.Form {
+			atoms, ok := splitPattern(pattern)
+			if !ok {
+				return nil, errors.New("参数模式错误: " + pattern)
+			}
+			tree.children = append(tree.children, &paramNode{key: atoms[0], atoms: atoms})
+		}
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"multipart/form-data": map[string]any{"schema": tree.schema()}},
+		}
+	}
+
+	// 响应
+	operation["responses"] = op.responses(len(parameters) > 0 || len(body) > 0 || len(op.Form) > 0)
+
+	return operation, nil
+}
+
+// responses 生成响应, hasParams 为 true 时补充参数错误
+func (op Operation) responses(hasParams bool) map[string]any {
+	responses := map[string]any{}
+
+	// 成功
+	status := op.Status
+	if status == 0 {
+		status = 200
+	}
+	success := map[string]any{"description": "成功"}
+	var schema map[string]any
+	if op.Response != nil {
+		schema = valueSchema(reflect.TypeOf(op.Response), 0)
+	}
+	if op.Paging {
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		schema = map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"page":          map[string]any{"type": "integer", "description": "页码"},
+				"per_page":      map[string]any{"type": "integer", "description": "页大小"},
+				"total_pages":   map[string]any{"type": "integer", "description": "总页数"},
+				"total_results": map[string]any{"type": "integer", "description": "总记录数"},
+				"items":         map[string]any{"type": "array", "items": schema, "description": "列表"},
+			},
+		}
+	}
+	if schema != nil {
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+	}
+	responses[strconv.Itoa(status)] = success
+
+	// 错误, 按 HTTP 状态码分组
+	appErrors := append([]*AppError{}, op.Errors...)
+	if hasParams {
+		appErrors = append(appErrors, ErrParamEmpty, ErrParamInvalid)
+	}
+	appErrors = append(appErrors, ErrInternal)
+	groups := lo.GroupBy(lo.UniqBy(appErrors, func(e *AppError) string {
+		return e.Code
+	}), func(e *AppError) int {
+		return e.HTTPCode
+	})
+	for httpCode, items := range groups {
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].Code < items[j].Code
+		})
+		codes := lo.Map(items, func(e *AppError, _ int) string {
+			return e.Code
+		})
+		descriptions := lo.Map(items, func(e *AppError, _ int) string {
+			return e.Code + ": " + e.Message
+		})
+		responses[strconv.Itoa(httpCode)] = map[string]any{
+			"description": strings.Join(descriptions, "; "),
+			"content":     errorContent(codes),
+		}
+	}
+
+	return responses
+}
+
+// errorContent 错误响应内容, 按当前错误输出格式生成
+func errorContent(codes []string) map[string]any {
+	schema := func(name string) map[string]any {
+		return map[string]any{"schema": map[string]any{"allOf": []map[string]any{
+			{"$ref": "#/components/schemas/" + name},
+			{"type": "object", "properties": map[string]any{"code": map[string]any{"type": "string", "enum": codes}}},
+		}}}
+	}
+	switch errorFormat.Load().(ErrorFormat) {
+	case ErrorFormatProblem:
+		return map[string]any{problemContentType: schema("Problem")}
+	case ErrorFormatNegotiate:
+		return map[string]any{"application/json": schema("Error"), problemContentType: schema("Problem")}
+	default:
+		return map[string]any{"application/json": schema("Error")}
+	}
+}
+
+// docStructPatterns 由结构体生成参数模式
+func docStructPatterns(v any, nested bool) ([]string, error) {
+	rt := reflect.TypeOf(v)
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, errors.New("参数绑定目标必须为结构体")
+	}
+
+	return fieldPatterns(rt, "", nested)
+}
+
+// schema 由参数模式树生成对象结构
+func (n *paramNode) schema() map[string]any {
+	properties := map[string]any{}
+	required := make([]string, 0)
+	for _, child := range n.children {
+		var schema map[string]any
+		switch {
+		case len(child.children) == 0:
+			schema = typeSchema(child.atoms[2])
+		case child.atoms != nil:
+			schema = typeSchema(child.atoms[2])
+		case child.isArray:
+			schema = map[string]any{"type": "array"}
+		default:
+			schema = map[string]any{"type": "object"}
+		}
+		if len(child.children) > 0 {
+			object := child.schema()
+			if child.isArray {
+				schema["items"] = object
+			} else {
+				for k, v := range object {
+					schema[k] = v
+				}
+			}
+		}
+		if child.atoms != nil {
+			schema["description"] = child.atoms[1]
+			if isRequired, _ := jsonParamMode(child.atoms[3]); isRequired {
+				required = append(required, child.key)
+			}
+		}
+		properties[child.key] = schema
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema 由参数类型生成结构, 类型见 FilterParam()
+func typeSchema(paramType string) map[string]any {
+	// 长度修饰
+	if baseType, min, max, ok := typeModifier(paramType, '{', '}'); ok {
+		schema := typeSchema(baseType)
+		minVal, maxVal, err := parseBounds(min, max)
+		if err != nil {
+			return schema
+		}
+		minKey, maxKey := "minLength", "maxLength"
+		switch {
+		case schema["type"] == "array":
+			minKey, maxKey = "minItems", "maxItems"
+		case schema["type"] == "object":
+			minKey, maxKey = "minProperties", "maxProperties"
+		case schema["format"] == "binary":
+			minKey, maxKey = "x-min-size", "x-max-size"
+		}
+		if minVal != nil {
+			schema[minKey] = *minVal
+		}
+		if maxVal != nil {
+			schema[maxKey] = *maxVal
+		}
+		return schema
+	}
+	// 范围修饰
+	if baseType, min, max, ok := typeModifier(paramType, '[', ']'); ok {
+		schema := typeSchema(baseType)
+		minVal, maxVal, err := parseBounds(min, max)
+		if err != nil {
+			return schema
+		}
+		if minVal != nil {
+			schema["minimum"] = *minVal
+		}
+		if maxVal != nil {
+			schema["maximum"] = *maxVal
+		}
+		return schema
+	}
+
+	if _, ok := customType(paramType); ok {
+		return map[string]any{"type": "string", "format": paramType}
+	}
+	// 精度, float.2/decimal.2
+	if baseType, precision, ok := strings.Cut(paramType, "."); ok && (baseType == "float" || baseType == "decimal") {
+		schema := map[string]any{"type": "number"}
+		if n, err := strconv.Atoi(precision); err == nil { // 超过精度四舍五入, 不使用 multipleOf 限制
+			schema["description"] = fmt.Sprintf("精度 %d 位小数", n)
+		}
+		return schema
+	}
+	switch paramType {
+	case "integer":
+		return map[string]any{"type": "integer"}
+	case "+integer":
+		return map[string]any{"type": "integer", "minimum": 1}
+	case "!-integer":
+		return map[string]any{"type": "integer", "minimum": 0}
+	case "string":
+		return map[string]any{"type": "string"}
+	case "float", "decimal":
+		return map[string]any{"type": "number"}
+	case "email":
+		return map[string]any{"type": "string", "format": "email"}
+	case "url":
+		return map[string]any{"type": "string", "format": "uri"}
+	case "date":
+		return map[string]any{"type": "string", "format": "date", "example": "2006-01-02"}
+	case "datetime":
+		return map[string]any{"type": "string", "example": "2006-01-02 15:04:05"}
+	case "ip":
+		return map[string]any{"type": "string", "format": "ip"}
+	case "uuid":
+		return map[string]any{"type": "string", "format": "uuid"}
+	case "array":
+		return map[string]any{"type": "array", "items": map[string]any{}}
+	case "object":
+		return map[string]any{"type": "object"}
+	case "file":
+		return map[string]any{"type": "string", "format": "binary"}
+	}
+	if inner, ok := strings.CutPrefix(paramType, "regex("); ok && strings.HasSuffix(inner, ")") {
+		return map[string]any{"type": "string", "pattern": strings.TrimSuffix(inner, ")")}
+	}
+	if inner, ok := strings.CutPrefix(paramType, "file("); ok && strings.HasSuffix(inner, ")") {
+		return map[string]any{"type": "string", "format": "binary", "description": "允许: " + strings.TrimSuffix(inner, ")")}
+	}
+	if strings.HasPrefix(paramType, "[") && !strings.HasPrefix(paramType, "[]") {
+		enum := make([]any, 0)
+		if err := json.Unmarshal([]byte(paramType), &enum); err == nil && len(enum) > 0 {
+			schemaType := "string"
+			if lo.EveryBy(enum, func(v any) bool { f, ok := v.(float64); return ok && f == float64(int64(f)) }) {
+				schemaType = "integer"
+			} else if lo.EveryBy(enum, func(v any) bool { _, ok := v.(float64); return ok }) {
+				schemaType = "number"
+			}
+			return map[string]any{"type": schemaType, "enum": enum}
+		}
+	}
+	if itemType, ok := strings.CutPrefix(paramType, "[]"); ok {
+		return map[string]any{"type": "array", "items": typeSchema(itemType)}
+	}
+
+	return map[string]any{"description": "未知类型: " + paramType}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// valueSchema 由 Go 类型生成结构, 结构体按 json tag 生成属性
+func valueSchema(rt reflect.Type, depth int) map[string]any {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if depth > 8 { // 避免循环引用
+		return map[string]any{}
+	}
+	if rt == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": valueSchema(rt.Elem(), depth+1)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": valueSchema(rt.Elem(), depth+1)}
+	case reflect.Struct:
+		if rt.Implements(marshalerType) || reflect.PointerTo(rt).Implements(marshalerType) { // 自定义编码的类型, 比如 carbon.DateTime
+			return map[string]any{"type": "string"}
+		}
+		properties := map[string]any{}
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" { // 嵌入结构体展开
+				embedded := valueSchema(field.Type, depth+1)
+				if props, ok := embedded["properties"].(map[string]any); ok {
+					for k, v := range props {
+						properties[k] = v
+					}
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = valueSchema(field.Type, depth+1)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{}
+	}
+}
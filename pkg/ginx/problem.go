@@ -0,0 +1,149 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+)
+
+// ErrorFormat 错误输出格式
+type ErrorFormat string
+
+const (
+	ErrorFormatDefault   ErrorFormat = "default"   // {"code": "", "message": ""}
+	ErrorFormatProblem   ErrorFormat = "problem"   // RFC 7807 application/problem+json
+	ErrorFormatNegotiate ErrorFormat = "negotiate" // 请求头 Accept 包含 application/problem+json 时使用 problem, 否则使用 default
+)
+
+const problemContentType = "application/problem+json"
+
+var (
+	errorFormat     atomic.Value // 错误输出格式
+	problemTypeBase atomic.Value // problem type URI 前缀
+)
+
+func init() {
+	errorFormat.Store(ErrorFormatDefault)
+	problemTypeBase.Store("")
+}
+
+// SetErrorFormat 设置错误输出格式
+//
+//	未知格式按 ErrorFormatDefault 处理. 应在启动时设置.
+func SetErrorFormat(format ErrorFormat) {
+	if !lo.Contains([]ErrorFormat{ErrorFormatProblem, ErrorFormatNegotiate}, format) {
+		format = ErrorFormatDefault
+	}
+	errorFormat.Store(format)
+}
+
+// SetProblemTypeBase 设置 problem type URI 前缀
+//
+//	type 为前缀拼接错误码, 比如 https://example.com/errors/ + UserNotFound. 为空时 type 为 about:blank.
+func SetProblemTypeBase(base string) {
+	problemTypeBase.Store(base)
+}
+
+// problem RFC 7807 错误信息
+//
+//	code/fields 为扩展字段, 与默认格式保持一致.
+type problem struct {
+	Type     string       `json:"type"`             // 错误类型 URI
+	Title    string       `json:"title"`            // HTTP 状态描述
+	Status   int          `json:"status"`           // HTTP 状态码
+	Detail   string       `json:"detail"`           // 错误信息
+	Instance string       `json:"instance"`         // 请求路径
+	Code     string       `json:"code"`             // 错误码
+	Fields   []FieldError `json:"fields,omitempty"` // 参数错误明细
+}
+
+// useProblem 当前请求是否使用 problem 格式
+func useProblem(c *gin.Context) bool {
+	switch errorFormat.Load().(ErrorFormat) {
+	case ErrorFormatProblem:
+		return true
+	case ErrorFormatNegotiate:
+		return strings.Contains(c.GetHeader("Accept"), problemContentType)
+	default:
+		return false
+	}
+}
+
+// statusText 状态码说明, 补充标准库中没有的非标准状态码
+func statusText(httpCode int) string {
+	if httpCode == 499 { // 客户端断开, 沿用 nginx 定义
+		return "Client Closed Request"
+	}
+	return http.StatusText(httpCode)
+}
+
+// errorBody 生成错误信息
+//
+//	message 为已本地化的信息, fields 为空表示没有参数错误明细.
+func errorBody(c *gin.Context, httpCode int, code, message string, fields []FieldError) (string, any) {
+	if useProblem(c) {
+		problemType := "about:blank"
+		if base := problemTypeBase.Load().(string); base != "" {
+			problemType = base + code
+		}
+		return problemContentType, problem{
+			Type:     problemType,
+			Title:    statusText(httpCode),
+			Status:   httpCode,
+			Detail:   message,
+			Instance: c.Request.URL.Path,
+			Code:     code,
+			Fields:   fields,
+		}
+	}
+
+	body := gin.H{"code": code, "message": message}
+	if len(fields) > 0 {
+		body["fields"] = fields
+	}
+	return "application/json; charset=utf-8", body
+}
+
+// errorHeader 设置错误响应头
+//
+//	协商模式下错误格式随请求头 Accept 变化, 附加 Vary: Accept, 以免缓存混用两种格式.
+func errorHeader(header http.Header, contentType string) {
+	header.Set("Content-Type", contentType)
+	if errorFormat.Load().(ErrorFormat) != ErrorFormatNegotiate {
+		return
+	}
+	for _, value := range header.Values("Vary") {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), "Accept") {
+				return
+			}
+		}
+	}
+	header.Add("Vary", "Accept")
+}
+
+// renderError 输出错误信息并终止后续处理
+func renderError(c *gin.Context, httpCode int, code, message string, fields []FieldError) {
+	contentType, body := errorBody(c, httpCode, code, message, fields)
+	markResponded(c)
+	errorHeader(c.Writer.Header(), contentType)
+	c.AbortWithStatusJSON(httpCode, body)
+}
+
+// ErrorBody 生成错误信息
+//
+//	用于不经过 Gin 输出的场景, 比如超时中间件. message 本地化规则与 Error() 相同, 设置 header 中的错误响应头并返回 JSON 内容.
+func ErrorBody(c *gin.Context, header http.Header, httpCode int, code, message string) []byte {
+	contentType, body := errorBody(c, httpCode, code, localize(c, code, message), nil)
+	errorHeader(header, contentType)
+	bodyBytes, _ := json.Marshal(body)
+
+	return bodyBytes
+}
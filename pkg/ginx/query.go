@@ -0,0 +1,166 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"regexp"
+	"strings"
+
+	"go-demo/pkg/gox"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const fieldsKey = "ginx:fields" // 客户端选择的返回字段 Gin 上下文键名
+
+var (
+	filterKeyRegexp = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`) // filter[column][op]
+	columnRegexp    = regexp.MustCompile(`^\w+$`)
+	likeEscaper     = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`) // LIKE 通配符转义, 值按字面匹配
+
+	// filterOperators 筛选操作符
+	filterOperators = map[string]string{
+		"eq":   "= ?",
+		"ne":   "<> ?",
+		"gt":   "> ?",
+		"gte":  ">= ?",
+		"lt":   "< ?",
+		"lte":  "<= ?",
+		"in":   "IN ?",
+		"like": "LIKE ?",
+	}
+)
+
+// clientQuery 客户端排序/筛选/字段选择
+//
+//	客户端参数:
+//		sort=-created_at,user_id 排序, - 表示倒序, 字段须在 pageQuery.Sortable 中, pageQuery.OrderBy 作为补充排序;
+//		filter[is_vip]=1 筛选, filter[user_id][gte]=100 指定操作符, 操作符 eq/ne/gt/gte/lt/lte/in/like, in 多个值逗号分隔,
+//		  字段须在 pageQuery.Filterable 中, 值按对应类型校验;
+//		fields=user_id,user_name 返回字段, 字段须在 pageQuery.Selectable 中, 输出时仅保留这些字段.
+//	返回最终排序.
+func clientQuery(c *gin.Context, tx *gorm.DB, pageQuery PageQuery) (*gorm.DB, string, error) {
+	// 筛选
+	if len(pageQuery.Filterable) > 0 {
+		for key, values := range c.Request.URL.Query() {
+			matches := filterKeyRegexp.FindStringSubmatch(key)
+			if matches == nil || len(values) == 0 {
+				continue
+			}
+			column, op := matches[1], matches[2]
+			if op == "" {
+				op = "eq"
+			}
+			paramType, ok := pageQuery.Filterable[column]
+			expr, opOK := filterOperators[op]
+			if !ok || !opOK {
+				return nil, "", renderParamError(c, invalidError("筛选字段"))
+			}
+			var value any
+			switch op {
+			case "in":
+				items := make([]any, 0)
+				for _, item := range strings.Split(values[0], ",") {
+					itemValue, e := filterParam(column, item, paramType, false)
+					if e != nil {
+						return nil, "", renderParamError(c, e)
+					}
+					items = append(items, itemValue)
+				}
+				value = items
+			case "like":
+				itemValue, e := filterParam(column, values[0], "string", false)
+				if e != nil {
+					return nil, "", renderParamError(c, e)
+				}
+				value = "%" + likeEscaper.Replace(itemValue.(string)) + "%"
+			default:
+				itemValue, e := filterParam(column, values[0], paramType, false)
+				if e != nil {
+					return nil, "", renderParamError(c, e)
+				}
+				value = itemValue
+			}
+			tx = tx.Where(column+" "+expr, value)
+		}
+	}
+
+	// 返回字段
+	if fields := strings.TrimSpace(c.Query("fields")); fields != "" && len(pageQuery.Selectable) > 0 {
+		columns := lo.Uniq(lo.Map(strings.Split(fields, ","), func(item string, _ int) string {
+			return strings.TrimSpace(item)
+		}))
+		for _, column := range columns {
+			if !lo.Contains(pageQuery.Selectable, column) {
+				return nil, "", renderParamError(c, invalidError("返回字段"))
+			}
+		}
+		c.Set(fieldsKey, columns) // 计数后再选择, 见 selectClientFields()
+	}
+
+	// 排序
+	orderBy := pageQuery.OrderBy
+	if sort := strings.TrimSpace(c.Query("sort")); sort != "" && len(pageQuery.Sortable) > 0 {
+		orders := make([]string, 0)
+		sortColumns := make([]string, 0)
+		for _, item := range strings.Split(sort, ",") {
+			item = strings.TrimSpace(item)
+			column, desc := strings.CutPrefix(item, "-")
+			if !columnRegexp.MatchString(column) || !lo.Contains(pageQuery.Sortable, column) || lo.Contains(sortColumns, column) {
+				return nil, "", renderParamError(c, invalidError("排序字段"))
+			}
+			sortColumns = append(sortColumns, column)
+			if desc {
+				orders = append(orders, column+" DESC")
+			} else {
+				orders = append(orders, column+" ASC")
+			}
+		}
+		// 默认排序中未被客户端指定的字段作为补充排序, 保证排序稳定
+		if pageQuery.OrderBy != "" {
+			for _, item := range strings.Split(pageQuery.OrderBy, ",") {
+				column, _, _ := strings.Cut(strings.TrimSpace(item), " ")
+				if !lo.Contains(sortColumns, column) {
+					orders = append(orders, strings.TrimSpace(item))
+				}
+			}
+		}
+		orderBy = strings.Join(orders, ", ")
+	}
+
+	return tx, orderBy, nil
+}
+
+// selectClientFields 查询客户端选择的返回字段
+//
+//	应在计数之后调用, 只选一个字段时 GORM 会计数为 COUNT(字段), 字段可为空时总数偏小.
+func selectClientFields(c *gin.Context, tx *gorm.DB) *gorm.DB {
+	value, ok := c.Get(fieldsKey)
+	if !ok {
+		return tx
+	}
+	return tx.Select(value.([]string))
+}
+
+// selectFields 按客户端选择的返回字段裁剪列表数据
+//
+//	列表元素的 json 键名须与字段名一致. 客户端未选择时原样返回.
+func selectFields(c *gin.Context, items any) any {
+	value, ok := c.Get(fieldsKey)
+	if !ok {
+		return items
+	}
+	columns := value.([]string)
+	rows := make([]map[string]any, 0)
+	if err := gox.CopyViaJSON(items, &rows); err != nil {
+		return items
+	}
+	for i, row := range rows {
+		rows[i] = lo.PickByKeys(row, columns)
+	}
+
+	return rows
+}
@@ -10,62 +10,139 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"go-demo/pkg/gox"
+	"go-demo/pkg/i18nx"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/cache/v9"
 	"github.com/goccy/go-json"
 	"github.com/samber/lo"
 	"github.com/spf13/cast"
 	"gorm.io/gorm"
 )
 
+const validateAllKey = "ginx:validateAll" // 完整校验模式 Gin 上下文键名
+
+// paramError 参数校验错误
+//
+//	httpCode 为 500 表示参数模式/数据类型等开发错误, err 为需要记录的日志.
+type paramError struct {
+	httpCode int
+	code     string
+	msgKey   string // 消息 key, 见 message.go
+	args     []any  // 消息参数, 首个为参数名称
+	err      error
+}
+
+func (e *paramError) Error() string {
+	return e.code
+}
+
+// message 本地化错误信息
+func (e *paramError) message(locale string) string {
+	args := make([]any, len(e.args))
+	copy(args, e.args)
+	if len(args) > 0 {
+		args[0] = i18nx.T(locale, cast.ToString(args[0])) // 参数名称在消息目录中有翻译时使用翻译
+	}
+	return i18nx.T(locale, e.msgKey, args...)
+}
+
+// emptyError 参数为空错误
+func emptyError(paramName string) *paramError {
+	return &paramError{httpCode: 400, code: "ParamEmpty", msgKey: "param.empty", args: []any{paramName}}
+}
+
+// invalidError 参数不正确错误
+func invalidError(paramName string) *paramError {
+	return &paramError{httpCode: 400, code: "ParamInvalid", msgKey: "param.invalid", args: []any{paramName}}
+}
+
+// typeError 数据类型错误
+func typeError(paramName string) *paramError {
+	return &paramError{httpCode: 500, code: "ParamTypeError", err: errors.New("数据类型错误: " + paramName)}
+}
+
+// renderParamError 输出参数校验错误
+func renderParamError(c *gin.Context, e *paramError) error {
+	if e.httpCode == 500 {
+		InternalError(c, e.err)
+	} else {
+		errorJSON(c, e.httpCode, e.code, e.message(Locale(c)))
+	}
+	return codeError(e.code).Wrap(e)
+}
+
+// ValidateAll 开启完整校验模式
+//
+//	默认遇到第一个错误参数即输出错误, 开启后 GetJSONBody/GetQueries/BindJSON/BindQuery 会校验全部参数,
+//	一次性输出所有参数错误, 格式见 FieldsError(). 需在获取参数前调用, 路由上开启可使用 middleware.ValidateAll().
+func ValidateAll(c *gin.Context) {
+	c.Set(validateAllKey, true)
+}
+
+// paramCollector 参数错误收集
+type paramCollector struct {
+	c      *gin.Context
+	all    bool // 是否完整校验模式
+	fields []FieldError
+	err    error // 立即结束校验时的错误
+}
+
+func newParamCollector(c *gin.Context) *paramCollector {
+	return &paramCollector{c: c, all: c.GetBool(validateAllKey)}
+}
+
+// add 记录参数错误
+//
+//	返回 true 表示需要立即结束校验. 非完整校验模式及开发错误会立即输出错误.
+func (pc *paramCollector) add(key, paramName string, e *paramError) bool {
+	if !pc.all || e.httpCode == 500 {
+		pc.err = renderParamError(pc.c, e)
+		return true
+	}
+	locale := Locale(pc.c)
+	pc.fields = append(pc.fields, FieldError{Key: key, Name: i18nx.T(locale, paramName), Code: e.code, Message: e.message(locale)})
+	return false
+}
+
+// done 校验结束, 有参数错误则输出
+func (pc *paramCollector) done() error {
+	if len(pc.fields) == 0 {
+		return nil
+	}
+	FieldsError(pc.c, 400, pc.fields)
+	return codeError(pc.fields[0].Code)
+}
+
 // GetJSONBody 获取 JSON 参数
 //
 //	patterns 模式格式 ["paramKey:paramName:paramType:paramPattern"]
+//	  paramKey: 键名. 支持点号路径校验嵌套参数, address.city 表示对象 address 的 city 字段, items[].sku 表示对象数组 items 中每个元素的 sku 字段.
+//	    子字段的必传/选传相对于所在对象, 所在对象未传时不校验. 对象自身可以另外声明模式, 比如 "items:商品:[]object{1,}:+".
+//	    声明了子字段的对象, 结果中仅包含声明的子字段, 对象为 map[string]any, 对象数组为 []map[string]any.
 //	  paramType: 类型. 详情见 FilterParam() 方法 paramType 参数.
 //	  paramPattern: 传值模式. + 表示字段必传,值不可为空; * 表示字段选传,值可为空; ? 表示字段选传,值不可为空.
 func GetJSONBody(c *gin.Context, patterns []string) (map[string]any, error) {
 	// body
 	jsonBody := make(map[string]any)
 	_ = c.ShouldBindJSON(&jsonBody) // 这里的 error 不要处理, 因为空 body 会报 error
+	// pattern
+	tree, err := buildParamTree(patterns)
+	if err != nil {
+		InternalError(c, err)
+		return nil, ErrParamPattern
+	}
 	// 逐字段校验
 	result := make(map[string]any)
-	var err error
-	for _, pattern := range patterns {
-		// pattern
-		patternAtoms := strings.Split(pattern, ":")
-		if len(patternAtoms) != 4 {
-			InternalError(c, errors.New("参数模式错误: "+pattern))
-			return nil, errors.New("ParamPatternError")
-		}
-		required := true
-		allowEmpty := false
-		if patternAtoms[3] == "+" {
-			required = true
-			allowEmpty = false
-		} else if patternAtoms[3] == "*" {
-			required = false
-			allowEmpty = true
-		} else if patternAtoms[3] == "?" {
-			required = false
-			allowEmpty = false
-		}
-		// key
-		paramValue, ok := jsonBody[patternAtoms[0]]
-		if !ok || paramValue == nil {
-			if required {
-				Error(c, 400, "ParamEmpty", patternAtoms[1]+"不得为空")
-				return nil, errors.New("ParamEmpty")
-			} else {
-				continue
-			}
-		}
-		// 类型值
-		result[patternAtoms[0]], err = FilterParam(c, patternAtoms[1], paramValue, patternAtoms[2], allowEmpty)
-		if err != nil {
-			return nil, err
-		}
+	collector := newParamCollector(c)
+	if tree.validate(collector, jsonBody, result, "") {
+		return nil, collector.err
+	}
+	if err := collector.done(); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -79,12 +156,12 @@ func GetJSONBody(c *gin.Context, patterns []string) (map[string]any, error) {
 func GetQueries(c *gin.Context, patterns []string) (map[string]any, error) {
 	// 逐字段校验
 	result := make(map[string]any)
-	var err error
+	collector := newParamCollector(c)
 	for _, pattern := range patterns {
-		patternAtoms := strings.Split(pattern, ":")
-		if len(patternAtoms) != 4 {
+		patternAtoms, ok := splitPattern(pattern)
+		if !ok {
 			InternalError(c, errors.New("参数模式错误: "+pattern))
-			return nil, errors.New("ParamPatternError")
+			return nil, ErrParamPattern
 		}
 		// default
 		allowEmpty := false
@@ -96,22 +173,94 @@ func GetQueries(c *gin.Context, patterns []string) (map[string]any, error) {
 		paramValue := c.Query(patternAtoms[0])
 		if paramValue == "" {
 			if patternAtoms[3] == "required" { // 必填
-				Error(c, 400, "ParamEmpty", patternAtoms[1]+"不得为空")
-				return nil, errors.New("ParamEmpty")
+				if collector.add(patternAtoms[0], patternAtoms[1], emptyError(patternAtoms[1])) {
+					return nil, collector.err
+				}
+				continue
 			} else {
 				paramValue = patternAtoms[3]
 			}
 		}
 		// 类型值
-		result[patternAtoms[0]], err = FilterParam(c, patternAtoms[1], paramValue, patternAtoms[2], allowEmpty)
-		if err != nil {
-			return nil, err
+		value, e := filterParam(patternAtoms[1], paramValue, patternAtoms[2], allowEmpty)
+		if e != nil {
+			if collector.add(patternAtoms[0], patternAtoms[1], e) {
+				return nil, collector.err
+			}
+			continue
+		}
+		result[patternAtoms[0]] = value
+	}
+	if err := collector.done(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetForm 获取表单参数
+//
+//	支持 multipart/form-data 与 application/x-www-form-urlencoded.
+//	patterns 模式格式 ["paramKey:paramName:paramType:paramPattern"]
+//	  paramType: 类型. 详情见 FilterParam() 方法 paramType 参数. 上传文件使用 file 类型, [] 开头的类型取同名的多个值.
+//	  paramPattern: 传值模式. + 表示字段必传,值不可为空; * 表示字段选传,值可为空; ? 表示字段选传,值不可为空.
+func GetForm(c *gin.Context, patterns []string) (map[string]any, error) {
+	// 逐字段校验
+	result := make(map[string]any)
+	collector := newParamCollector(c)
+	for _, pattern := range patterns {
+		patternAtoms, ok := splitPattern(pattern)
+		if !ok {
+			InternalError(c, errors.New("参数模式错误: "+pattern))
+			return nil, ErrParamPattern
+		}
+		required, allowEmpty := jsonParamMode(patternAtoms[3])
+		// key
+		paramValue := formValue(c, patternAtoms[0], strings.HasPrefix(patternAtoms[2], "[]"))
+		if paramValue == nil {
+			if required && collector.add(patternAtoms[0], patternAtoms[1], emptyError(patternAtoms[1])) {
+				return nil, collector.err
+			}
+			continue
+		}
+		// 类型值
+		value, e := filterParam(patternAtoms[1], paramValue, patternAtoms[2], allowEmpty)
+		if e != nil {
+			if collector.add(patternAtoms[0], patternAtoms[1], e) {
+				return nil, collector.err
+			}
+			continue
 		}
+		result[patternAtoms[0]] = value
+	}
+	if err := collector.done(); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
+// formValue 获取表单值, 未传返回 nil
+//
+//	同名的文件优先于文本值. isArray 为 true 时返回 []any, 否则返回第一个值.
+func formValue(c *gin.Context, key string, isArray bool) any {
+	values := c.PostFormArray(key) // 会解析表单
+	if form := c.Request.MultipartForm; form != nil && len(form.File[key]) > 0 {
+		if !isArray {
+			return form.File[key][0]
+		}
+		return lo.ToAnySlice(form.File[key])
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if !isArray {
+		return values[0]
+	}
+
+	return lo.ToAnySlice(values)
+}
+
 // FilterParam 校验参数类型
 //
 //	paramType 参数类型:
@@ -125,46 +274,102 @@ func GetQueries(c *gin.Context, patterns []string) (map[string]any, error) {
 //		array 数组;
 //		[]integer 整型64位数组;
 //		[]string 字符串数组;
+//		object 对象, 返回类型为 map[string]any;
+//		[]object 对象数组, 返回类型为 []map[string]any;
+//		email 邮箱;
+//		url 网址, 须包含协议与域名;
+//		date 日期, 格式 2006-01-02;
+//		datetime 日期时间, 格式 2006-01-02 15:04:05;
+//		ip IPv4/IPv6 地址;
+//		uuid UUID, 返回小写;
+//		regex(^...$) 匹配正则的字符串, 正则中可以包含冒号;
+//		file(.jpg,.png,image/*) 上传文件, 仅用于 GetForm(), 括号内为允许的扩展名与 MIME 类型(内容嗅探), 点号开头的为扩展名, 括号可省略表示不限制, 返回类型为 *File;
+//		[]file(...) 上传文件数组, 返回类型为 []*File;
+//		[]<type> 其他类型数组, 返回类型为 []any, 比如 []email, []mobile;
+//		自定义类型, 见 RegisterType();
+//	类型修饰, min/max 均可省略, 仅一个数字时表示 min 与 max 相等:
+//		<type>[min,max] 数值范围, 适用于整型/浮点数/精度小数, 比如 integer[1,100], decimal.2[0,];
+//		<type>{min,max} 长度, 字符串为字符数, 数组为元素个数, 文件为字节数(可带单位 K/M/G), 比如 string{2,50}, []integer{,10}, regex(^\w+$){6,20}, file(image/*){,2M};
 func FilterParam(c *gin.Context, paramName string, paramValue any, paramType string, allowEmpty bool) (any, error) {
+	value, e := filterParam(paramName, paramValue, paramType, allowEmpty)
+	if e != nil {
+		return nil, renderParamError(c, e)
+	}
+
+	return value, nil
+}
+
+// filterParam 校验参数类型, 不输出错误
+func filterParam(paramName string, paramValue any, paramType string, allowEmpty bool) (any, *paramError) {
+	// 长度, <type>{min,max}
+	if baseType, min, max, ok := typeModifier(paramType, '{', '}'); ok {
+		value, e := filterParam(paramName, paramValue, baseType, allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		if e := checkLength(paramName, value, min, max); e != nil {
+			return nil, e
+		}
+		return value, nil
+	}
+
+	// 数值范围, <type>[min,max]
+	if baseType, min, max, ok := typeModifier(paramType, '[', ']'); ok {
+		value, e := filterParam(paramName, paramValue, baseType, allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		if e := checkRange(paramName, paramValue, value, min, max); e != nil {
+			return nil, e
+		}
+		return value, nil
+	}
+
+	// 自定义类型
+	if f, ok := customType(paramType); ok {
+		value, err := f(paramName, paramValue, allowEmpty)
+		if err != nil {
+			return nil, customTypeError(paramName, err)
+		}
+		return value, nil
+	}
+
 	// 整型64位
 	if paramType == "integer" {
-		valueStr, err := FilterParam(c, paramName, paramValue, "string", allowEmpty) // 先统一转字符串再转整型, 这样小数就不允许输入了
-		if err != nil {
-			return nil, err
+		valueStr, e := filterParam(paramName, paramValue, "string", allowEmpty) // 先统一转字符串再转整型, 这样小数就不允许输入了
+		if e != nil {
+			return nil, e
 		}
 		if valueStr.(string) == "" {
 			return int64(0), nil
 		}
 		valueInt, err := strconv.ParseInt(cast.ToString(valueStr), 10, 64) // 解决前导0被识别为8进制的问题
 		if err != nil {
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		return valueInt, nil
 	}
 
 	// 正整型64位
 	if paramType == "+integer" {
-		valueInt, err := FilterParam(c, paramName, paramValue, "integer", allowEmpty)
-		if err != nil {
-			return nil, err
+		valueInt, e := filterParam(paramName, paramValue, "integer", allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 		if valueInt.(int64) <= 0 {
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		return valueInt, nil
 	}
 
 	// 非负整型64位
 	if paramType == "!-integer" {
-		valueInt, err := FilterParam(c, paramName, paramValue, "integer", allowEmpty)
-		if err != nil {
-			return nil, err
+		valueInt, e := filterParam(paramName, paramValue, "integer", allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 		if valueInt.(int64) < 0 {
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		return valueInt, nil
 	}
@@ -173,13 +378,11 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 	if paramType == "string" {
 		valueStr, err := cast.ToStringE(paramValue)
 		if err != nil {
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		valueStr = strings.TrimSpace(valueStr)
 		if valueStr == "" && !allowEmpty {
-			Error(c, 400, "ParamEmpty", paramName+"不得为空")
-			return nil, errors.New("ParamEmpty")
+			return nil, emptyError(paramName)
 		}
 
 		return valueStr, nil
@@ -188,9 +391,9 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 	// 浮点数, float.%d, 数字表示精度(没有后补零), 超过精度四舍五入, 点号同数字可省略, 表示无限制, 返回类型为 float64
 	if lo.Substring(paramType, 0, 5) == "float" {
 		// 值
-		valueStr, err := FilterParam(c, paramName, paramValue, "string", allowEmpty)
-		if err != nil {
-			return nil, err
+		valueStr, e := filterParam(paramName, paramValue, "string", allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 		// 空值
 		if valueStr.(string) == "" && allowEmpty {
@@ -199,8 +402,7 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 		// float
 		valueFloat, err := cast.ToFloat64E(valueStr)
 		if err != nil {
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		// 精度
 		prec := -1
@@ -208,8 +410,7 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 		if precStr != "" {
 			prec, err = cast.ToIntE(precStr)
 			if err != nil {
-				InternalError(c, errors.New("数据类型错误: "+paramName))
-				return nil, errors.New("ParamTypeError")
+				return nil, typeError(paramName)
 			}
 		}
 		if prec == -1 {
@@ -227,25 +428,58 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 			var err error
 			prec, err = cast.ToIntE(precStr)
 			if err != nil {
-				InternalError(c, errors.New("数据类型错误: "+paramName))
-				return nil, errors.New("ParamTypeError")
+				return nil, typeError(paramName)
 			}
 		}
-		valueFloat, err := FilterParam(c, paramName, paramValue, fmt.Sprintf("float.%d", prec), allowEmpty)
-		if err != nil {
-			return nil, err
+		valueFloat, e := filterParam(paramName, paramValue, fmt.Sprintf("float.%d", prec), allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 
 		return strconv.FormatFloat(valueFloat.(float64), 'f', prec, 64), nil // 这里不会有精度问题, 精度在float递归时已经处理了
 	}
 
+	// 内置格式
+	if lo.Contains([]string{"email", "url", "date", "datetime", "ip", "uuid"}, paramType) {
+		valueStr, e := filterParam(paramName, paramValue, "string", allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		if valueStr.(string) == "" {
+			return "", nil
+		}
+		value, ok := checkFormat(paramType, valueStr.(string))
+		if !ok {
+			return nil, invalidError(paramName)
+		}
+		return value, nil
+	}
+
+	// 正则, regex(^...$)
+	if strings.HasPrefix(paramType, "regex(") && strings.HasSuffix(paramType, ")") {
+		re, err := compileRegexp(paramType[6 : len(paramType)-1])
+		if err != nil {
+			return nil, typeError(paramName)
+		}
+		valueStr, e := filterParam(paramName, paramValue, "string", allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		if valueStr.(string) == "" {
+			return "", nil
+		}
+		if !re.MatchString(valueStr.(string)) {
+			return nil, invalidError(paramName)
+		}
+		return valueStr, nil
+	}
+
 	// 枚举, 支持数字 float64 与字符串 string 混合枚举
 	if paramType[0:1] == "[" && paramType[1:2] != "]" {
 		valueType := reflect.TypeOf(paramValue).String() // 用户输入值类型
 		enum := make([]any, 0)
 		if err := json.Unmarshal([]byte(paramType), &enum); err != nil { // 候选值解析到切片
-			Error(c, 400, "ParamInvalid", paramName+"不正确")
-			return nil, errors.New("ParamInvalid")
+			return nil, invalidError(paramName)
 		}
 		for _, value := range enum { // 用户输入与候选值逐个比较
 			enumType := reflect.TypeOf(value).String() // 候选值类型
@@ -265,19 +499,16 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 			} else if valueType == "string" {
 				valueFloat, err := cast.ToFloat64E(paramValue)
 				if err != nil {
-					Error(c, 400, "ParamInvalid", paramName+"不正确")
-					return nil, errors.New("ParamInvalid")
+					return nil, invalidError(paramName)
 				}
 				if valueFloat == value {
 					return valueFloat, nil
 				}
 			} else {
-				Error(c, 400, "ParamInvalid", paramName+"不正确")
-				return nil, errors.New("ParamInvalid")
+				return nil, invalidError(paramName)
 			}
 		}
-		Error(c, 400, "ParamInvalid", paramName+"不正确")
-		return nil, errors.New("ParamInvalid")
+		return nil, invalidError(paramName)
 	}
 
 	// 数组
@@ -285,26 +516,53 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 		valueType := reflect.TypeOf(paramValue).String() // 用户输入值类型
 		if valueType == "[]interface {}" {
 			if !allowEmpty && len(paramValue.([]any)) == 0 {
-				Error(c, 400, "ParamEmpty", paramName+"不得为空")
-				return nil, errors.New("ParamEmpty")
+				return nil, emptyError(paramName)
 			}
 			return paramValue, nil
 		}
-		Error(c, 400, "ParamInvalid", paramName+"不正确")
-		return nil, errors.New("ParamInvalid")
+		return nil, invalidError(paramName)
+	}
+
+	// 对象
+	if paramType == "object" {
+		valueMap, ok := paramValue.(map[string]any)
+		if !ok {
+			return nil, invalidError(paramName)
+		}
+		if !allowEmpty && len(valueMap) == 0 {
+			return nil, emptyError(paramName)
+		}
+		return valueMap, nil
+	}
+
+	// 对象数组
+	if paramType == "[]object" {
+		valueArr, e := filterParam(paramName, paramValue, "array", allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		mapSlice := make([]map[string]any, 0)
+		for _, item := range valueArr.([]any) {
+			itemAny, e := filterParam(paramName, item, "object", true)
+			if e != nil {
+				return nil, e
+			}
+			mapSlice = append(mapSlice, itemAny.(map[string]any))
+		}
+		return mapSlice, nil
 	}
 
 	// int64 数组
 	if paramType == "[]integer" {
-		valueArr, err := FilterParam(c, paramName, paramValue, "array", allowEmpty)
-		if err != nil {
-			return nil, err
+		valueArr, e := filterParam(paramName, paramValue, "array", allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 		intSlice := make([]int64, 0)
 		for _, item := range valueArr.([]any) {
-			itemAny, err := FilterParam(c, paramName, item, "integer", false)
-			if err != nil {
-				return nil, err
+			itemAny, e := filterParam(paramName, item, "integer", false)
+			if e != nil {
+				return nil, e
 			}
 			intSlice = append(intSlice, itemAny.(int64))
 		}
@@ -313,35 +571,90 @@ func FilterParam(c *gin.Context, paramName string, paramValue any, paramType str
 
 	// string 数组
 	if paramType == "[]string" {
-		arrayValue, err := FilterParam(c, paramName, paramValue, "array", allowEmpty)
-		if err != nil {
-			return nil, err
+		arrayValue, e := filterParam(paramName, paramValue, "array", allowEmpty)
+		if e != nil {
+			return nil, e
 		}
 		stringSlice := make([]string, 0)
 		for _, item := range arrayValue.([]any) {
-			itemAny, err := FilterParam(c, paramName, item, "string", false)
-			if err != nil {
-				return nil, err
+			itemAny, e := filterParam(paramName, item, "string", false)
+			if e != nil {
+				return nil, e
 			}
 			stringSlice = append(stringSlice, itemAny.(string))
 		}
 		return stringSlice, nil
 	}
 
-	InternalError(c, errors.New("未知数据类型: "+paramName))
-	return nil, errors.New("ParamTypeUndefined")
+	// 文件, file(.jpg,image/*)
+	if paramType == "file" || (strings.HasPrefix(paramType, "file(") && strings.HasSuffix(paramType, ")")) {
+		allowed := make([]string, 0)
+		if paramType != "file" {
+			allowed = strings.Split(paramType[5:len(paramType)-1], ",")
+		}
+		file, e := checkFile(paramName, paramValue, allowed, allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		return file, nil
+	}
+
+	// 文件数组, []file(.jpg,image/*)
+	if strings.HasPrefix(paramType, "[]file") {
+		valueArr, e := filterParam(paramName, paramValue, "array", allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		fileSlice := make([]*File, 0)
+		for _, item := range valueArr.([]any) {
+			itemAny, e := filterParam(paramName, item, paramType[2:], false)
+			if e != nil {
+				return nil, e
+			}
+			fileSlice = append(fileSlice, itemAny.(*File))
+		}
+		return fileSlice, nil
+	}
+
+	// 其他类型数组, []<type>
+	if itemType, ok := strings.CutPrefix(paramType, "[]"); ok && itemType != "" {
+		valueArr, e := filterParam(paramName, paramValue, "array", allowEmpty)
+		if e != nil {
+			return nil, e
+		}
+		slice := make([]any, 0)
+		for _, item := range valueArr.([]any) {
+			itemAny, e := filterParam(paramName, item, itemType, false)
+			if e != nil {
+				return nil, e
+			}
+			slice = append(slice, itemAny)
+		}
+		return slice, nil
+	}
+
+	return nil, &paramError{httpCode: 500, code: "ParamTypeUndefined", err: errors.New("未知数据类型: " + paramName)}
 }
 
 // PageQuery 分页参数
 type PageQuery struct {
-	DB         *gorm.DB
-	Model      any    // 表 model 指针
-	Table      string // 表名, 与 Model 二选一
-	Select     string // 表字段, 配合 Table 使用, Model 会智能选择表字段无需此字段
-	Joins      string
-	Where      string
-	BindParams []any
-	OrderBy    string
+	DB          *gorm.DB
+	Model       any    // 表 model 指针
+	Table       string // 表名, 与 Model 二选一
+	Select      string // 表字段, 配合 Table 使用, Model 会智能选择表字段无需此字段
+	Joins       string
+	Where       string
+	BindParams  []any
+	OrderBy     string
+	Sortable    []string          // 允许客户端排序的字段, 详见 clientQuery()
+	Filterable  map[string]string // 允许客户端筛选的字段及其参数类型, 类型见 FilterParam(), 比如 {"is_vip": "[0,1]"}
+	Selectable  []string          // 允许客户端选择的返回字段
+	Count       CountMode         // 计数策略, 默认精确计数
+	CountCache  *cache.Cache      // 计数缓存, 用于 CountCached
+	CountTTL    time.Duration     // 计数缓存时长, 默认1分钟
+	Export      []ExportColumn    // 导出列, 不为空时支持客户端参数 export=csv|xlsx 导出, 详见 exportList()
+	ExportName  string            // 导出文件名, 不含扩展名, 默认 export
+	ExportLimit int64             // 导出最大记录数, 默认10万
 }
 
 // Paging 分页结果
@@ -349,11 +662,22 @@ type Paging struct {
 	Page         int64 `json:"page"`          // 页码
 	PerPage      int64 `json:"per_page"`      // 页大小
 	TotalPages   int64 `json:"total_pages"`   // 总页数
-	TotalResults int64 `json:"total_results"` // 总记录数
+	TotalResults int64 `json:"total_results"` // 总记录数, CountEstimated 时为估算值
+	HasMore      bool  `json:"has_more"`      // 是否还有下一页
+
+	mode CountMode // 计数策略
 }
 
 // Paginate 获取分页数据
+//
+//	计数策略见 CountMode, 大表可使用缓存计数/估算计数/不计数.
+//	设置了 pageQuery.Export 且客户端请求导出时输出导出文件并返回 ErrExported.
 func Paginate(c *gin.Context, items any, pageQuery PageQuery) (*Paging, error) {
+	// 导出
+	if len(pageQuery.Export) > 0 && c.Query("export") != "" {
+		return nil, exportList(c, items, pageQuery)
+	}
+
 	// 页码
 	queries, err := GetQueries(c, []string{"page:页码:+integer:1", "per_page:页大小:+integer:12"})
 	if err != nil {
@@ -362,54 +686,94 @@ func Paginate(c *gin.Context, items any, pageQuery PageQuery) (*Paging, error) {
 	page := queries["page"].(int64)
 	perPage := queries["per_page"].(int64)
 
-	tx := pageQuery.DB
-	if pageQuery.Model != nil {
-		tx = tx.Model(pageQuery.Model)
-	}
-	if pageQuery.Table != "" {
-		tx = tx.Table(pageQuery.Table)
-	}
-	if pageQuery.Joins != "" {
-		tx = tx.Joins(pageQuery.Joins)
+	tx, orderBy, err := buildQuery(c, pageQuery)
+	if err != nil {
+		return nil, err
 	}
-	if pageQuery.Select != "" {
-		tx = tx.Select(pageQuery.Select)
+
+	offset := (page - 1) * perPage
+	listTx := selectClientFields(c, tx) // 计数不带字段选择与排序
+	if orderBy != "" {
+		listTx = listTx.Order(orderBy)
 	}
-	if pageQuery.Where != "" {
-		tx = tx.Where(pageQuery.Where, pageQuery.BindParams...)
+
+	// 不计数, 多取一条判断是否还有数据
+	if pageQuery.Count == CountHasMore {
+		if err := listTx.Offset(int(offset)).Limit(int(perPage) + 1).Find(items).Error; err != nil {
+			InternalError(c, nil)
+			return nil, ErrInternal
+		}
+		rv := reflect.ValueOf(items).Elem()
+		hasMore := int64(rv.Len()) > perPage
+		if hasMore {
+			rv.Set(rv.Slice(0, int(perPage)))
+		}
+		result := &Paging{
+			Page:    page,
+			PerPage: perPage,
+			HasMore: hasMore,
+			mode:    CountHasMore,
+		}
+		return result, nil
 	}
-	tx = tx.Session(&gorm.Session{})
 
 	// 总记录数
-	var totalResults int64 // 计算总记录数
-	if err := tx.Count(&totalResults).Error; err != nil {
-		InternalError(c, nil)
-		return nil, errors.New("InternalError")
+	totalResults, err := countResults(c, tx, pageQuery)
+	if err != nil {
+		InternalError(c, err)
+		return nil, ErrInternal
 	}
-	if totalResults == 0 { // 没有数据
+	if totalResults == 0 && pageQuery.Count != CountEstimated { // 没有数据, 估算计数可能偏小, 仍需查询
 		result := &Paging{
 			Page:         page,
 			PerPage:      perPage,
 			TotalPages:   0,
 			TotalResults: 0,
+			mode:         pageQuery.Count,
 		}
 		return result, nil
 	}
 
 	// items
-	if pageQuery.OrderBy != "" {
-		tx = tx.Order(pageQuery.OrderBy)
-	}
-	offset := (page - 1) * perPage
-	if err := tx.Offset(int(offset)).Limit(int(perPage)).Find(items).Error; err != nil {
+	if err := listTx.Offset(int(offset)).Limit(int(perPage)).Find(items).Error; err != nil {
 		InternalError(c, nil)
-		return nil, errors.New("InternalError")
+		return nil, ErrInternal
 	}
 	result := &Paging{
 		Page:         page,
 		PerPage:      perPage,
 		TotalPages:   int64(math.Ceil(float64(totalResults) / float64(perPage))),
 		TotalResults: totalResults,
+		HasMore:      offset+int64(reflect.ValueOf(items).Elem().Len()) < totalResults,
+		mode:         pageQuery.Count,
 	}
 	return result, nil
 }
+
+// buildQuery 由分页参数生成查询
+//
+//	包含客户端筛选, 返回最终排序. 客户端选择的返回字段在计数后由调用方查询, 见 selectClientFields().
+func buildQuery(c *gin.Context, pageQuery PageQuery) (*gorm.DB, string, error) {
+	tx := DB(c, pageQuery.DB)
+	if pageQuery.Model != nil {
+		tx = tx.Model(pageQuery.Model)
+	}
+	if pageQuery.Table != "" {
+		tx = tx.Table(pageQuery.Table)
+	}
+	if pageQuery.Joins != "" {
+		tx = tx.Joins(pageQuery.Joins)
+	}
+	if pageQuery.Select != "" {
+		tx = tx.Select(pageQuery.Select)
+	}
+	if pageQuery.Where != "" {
+		tx = tx.Where(pageQuery.Where, pageQuery.BindParams...)
+	}
+	tx, orderBy, err := clientQuery(c, tx, pageQuery)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tx.Session(&gorm.Session{}), orderBy, nil
+}
@@ -4,10 +4,26 @@
 package ginx
 
 import (
+	"go-demo/pkg/i18nx"
+	"go-demo/pkg/logx"
+
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
+const respondedKey = "ginx:responded" // 已输出响应 Gin 上下文键名
+
+// markResponded 标记已输出响应
+//
+//	超时中间件会缓冲响应, 处理结束前 c.Writer.Written() 始终为 false, 所以单独标记.
+func markResponded(c *gin.Context) {
+	c.Set(respondedKey, true)
+}
+
+// responded 是否已输出响应
+func responded(c *gin.Context) bool {
+	return c.GetBool(respondedKey) || c.Writer.Written()
+}
+
 // Success 输出成功信息
 //
 //	body 数据会 json 编码输出给客户端, nil 表示无内容输出.
@@ -15,13 +31,31 @@ func Success(c *gin.Context, httpCode int, body any) {
 	if body == nil {
 		body = gin.H{}
 	}
+	markResponded(c)
 	c.JSON(httpCode, body)
 }
 
 // PageSuccess 输出分页结果
 //
-//	items 列表数据
+//	items 列表数据, 客户端选择了返回字段时仅输出这些字段. 计数策略为 CountHasMore 时以 has_more 代替总页数与总记录数.
 func PageSuccess(c *gin.Context, items any, paging *Paging) {
+	if paging.mode == CountHasMore { // 不计数时不输出总页数与总记录数
+		body := struct {
+			Page    int64 `json:"page"`     // 页码
+			PerPage int64 `json:"per_page"` // 页大小
+			HasMore bool  `json:"has_more"` // 是否还有下一页
+			Items   any   `json:"items"`    // 列表
+		}{
+			paging.Page,
+			paging.PerPage,
+			paging.HasMore,
+			selectFields(c, items),
+		}
+		markResponded(c)
+		c.JSON(200, body)
+		return
+	}
+
 	body := struct {
 		Page         int64 `json:"page"`          // 页码
 		PerPage      int64 `json:"per_page"`      // 页大小
@@ -33,14 +67,66 @@ func PageSuccess(c *gin.Context, items any, paging *Paging) {
 		paging.PerPage,
 		paging.TotalPages,
 		paging.TotalResults,
-		items,
+		selectFields(c, items),
 	}
+	markResponded(c)
+	c.JSON(200, body)
+}
+
+// CursorSuccess 输出游标分页结果
+//
+//	items 列表数据, 客户端选择了返回字段时仅输出这些字段
+func CursorSuccess(c *gin.Context, items any, paging *CursorPaging) {
+	body := struct {
+		PerPage    int64  `json:"per_page"`    // 页大小
+		NextCursor string `json:"next_cursor"` // 下一页游标, 空表示没有下一页
+		PrevCursor string `json:"prev_cursor"` // 上一页游标, 空表示没有上一页
+		Items      any    `json:"items"`       // 列表
+	}{
+		paging.PerPage,
+		paging.NextCursor,
+		paging.PrevCursor,
+		selectFields(c, items),
+	}
+	markResponded(c)
 	c.JSON(200, body)
 }
 
 // Error 输出失败信息
+//
+//	message 为默认语言信息, 客户端语言的消息目录中有 code 对应的消息时使用该消息, 详见 Locale().
+//	输出格式见 SetErrorFormat().
 func Error(c *gin.Context, httpCode int, code, message string) {
-	c.AbortWithStatusJSON(httpCode, gin.H{"code": code, "message": message})
+	errorJSON(c, httpCode, code, localize(c, code, message))
+}
+
+// localize 本地化错误信息
+func localize(c *gin.Context, code, message string) string {
+	if localized, ok := i18nx.Lookup(Locale(c), code); ok {
+		return localized
+	}
+
+	return message
+}
+
+// errorJSON 输出失败信息, message 为已本地化的信息
+func errorJSON(c *gin.Context, httpCode int, code, message string) {
+	renderError(c, httpCode, code, message, nil)
+}
+
+// FieldError 参数错误明细
+type FieldError struct {
+	Key     string `json:"key"`     // 参数键名
+	Name    string `json:"name"`    // 参数名称
+	Code    string `json:"code"`    // 错误码
+	Message string `json:"message"` // 错误信息
+}
+
+// FieldsError 输出多个参数错误
+//
+//	code/message 取第一个参数错误, 与逐个校验时的输出保持兼容, fields 为全部参数错误.
+func FieldsError(c *gin.Context, httpCode int, fields []FieldError) {
+	renderError(c, httpCode, fields[0].Code, fields[0].Message, fields)
 }
 
 // InternalError 输出500错误
@@ -48,7 +134,7 @@ func Error(c *gin.Context, httpCode int, code, message string) {
 //	err 记录错误日志, nil 表示无需记录, 项目中定义的方法错误会就近记录, 无需重复记录.
 func InternalError(c *gin.Context, err error) {
 	if err != nil {
-		zap.L().Error(err.Error())
+		logx.L(c.Request.Context()).Error(err.Error())
 	}
-	Error(c, 500, "InternalError", "服务异常, 请稍后重试")
+	Error(c, ErrInternal.HTTPCode, ErrInternal.Code, ErrInternal.Message)
 }
@@ -0,0 +1,169 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// paramNode JSON 参数模式树节点
+//
+//	patterns 中的键名支持点号路径, address.city 表示对象 address 的 city 字段, items[].sku 表示对象数组 items 中每个元素的 sku 字段.
+type paramNode struct {
+	key      string       // 当前层级键名
+	atoms    []string     // 参数模式, 仅声明了子字段而未声明自身模式时为 nil
+	isArray  bool         // 是否对象数组
+	children []*paramNode // 子字段, 按 patterns 顺序
+}
+
+// child 获取子节点, 不存在则创建
+func (n *paramNode) child(key string) *paramNode {
+	for _, child := range n.children {
+		if child.key == key {
+			return child
+		}
+	}
+	child := &paramNode{key: key}
+	n.children = append(n.children, child)
+
+	return child
+}
+
+// buildParamTree 由参数模式生成模式树
+func buildParamTree(patterns []string) (*paramNode, error) {
+	root := &paramNode{}
+	for _, pattern := range patterns {
+		patternAtoms, ok := splitPattern(pattern)
+		if !ok {
+			return nil, errors.New("参数模式错误: " + pattern)
+		}
+		node := root
+		segments := strings.Split(patternAtoms[0], ".")
+		for i, segment := range segments {
+			key, isArray := strings.CutSuffix(segment, "[]")
+			if key == "" {
+				return nil, errors.New("参数模式错误: " + pattern)
+			}
+			node = node.child(key)
+			if isArray || (i == len(segments)-1 && strings.HasPrefix(patternAtoms[2], "[]object")) {
+				node.isArray = true
+			}
+		}
+		if node.atoms != nil {
+			return nil, errors.New("参数模式重复: " + pattern)
+		}
+		patternAtoms[0] = segments[len(segments)-1]
+		node.atoms = patternAtoms
+	}
+
+	return root, nil
+}
+
+// validate 按模式树逐字段校验
+//
+//	src 为客户端参数, dst 接收校验结果, path 为错误明细中的键名前缀. 返回 true 表示需要立即结束校验.
+func (n *paramNode) validate(collector *paramCollector, src, dst map[string]any, path string) bool {
+	for _, node := range n.children {
+		key := node.key
+		if path != "" {
+			key = path + "." + node.key
+		}
+		paramName := node.key
+		required := false
+		allowEmpty := true // 未声明自身模式的对象仅作为子字段的容器
+		if node.atoms != nil {
+			paramName = node.atoms[1]
+			required, allowEmpty = jsonParamMode(node.atoms[3])
+		}
+
+		paramValue, ok := src[node.key]
+		if !ok || paramValue == nil {
+			if required && collector.add(key, paramName, emptyError(paramName)) {
+				return true
+			}
+			continue
+		}
+
+		// 叶子字段
+		if len(node.children) == 0 {
+			value, e := filterParam(paramName, paramValue, node.atoms[2], allowEmpty)
+			if e != nil {
+				if collector.add(key, paramName, e) {
+					return true
+				}
+				continue
+			}
+			dst[node.key] = value
+			continue
+		}
+
+		// 对象/对象数组, 先校验自身再校验子字段, 结果仅包含声明的子字段
+		paramType := "object"
+		if node.isArray {
+			paramType = "[]object"
+		}
+		if node.atoms != nil {
+			paramType = node.atoms[2]
+		}
+		value, e := filterParam(paramName, paramValue, paramType, allowEmpty)
+		if e != nil {
+			if collector.add(key, paramName, e) {
+				return true
+			}
+			continue
+		}
+		if node.isArray {
+			items, ok := value.([]map[string]any)
+			if !ok {
+				if collector.add(key, paramName, typeError(paramName)) {
+					return true
+				}
+				continue
+			}
+			result := make([]map[string]any, len(items))
+			for i, item := range items {
+				result[i] = make(map[string]any)
+				if node.validate(collector, item, result[i], fmt.Sprintf("%s[%d]", key, i)) {
+					return true
+				}
+			}
+			dst[node.key] = result
+		} else {
+			item, ok := value.(map[string]any)
+			if !ok {
+				if collector.add(key, paramName, typeError(paramName)) {
+					return true
+				}
+				continue
+			}
+			result := make(map[string]any)
+			if node.validate(collector, item, result, key) {
+				return true
+			}
+			dst[node.key] = result
+		}
+	}
+
+	return false
+}
+
+// jsonParamMode 解析 JSON/表单参数传值模式
+func jsonParamMode(paramPattern string) (required, allowEmpty bool) {
+	required = true
+	allowEmpty = false
+	if paramPattern == "+" {
+		required = true
+		allowEmpty = false
+	} else if paramPattern == "*" {
+		required = false
+		allowEmpty = true
+	} else if paramPattern == "?" {
+		required = false
+		allowEmpty = false
+	}
+
+	return required, allowEmpty
+}
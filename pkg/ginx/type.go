@@ -0,0 +1,85 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// TypeFunc 自定义参数类型校验函数
+//
+//	返回校验并转换后的值. 校验失败返回 ErrParamEmpty/ErrParamInvalid 输出对应的 400 错误,
+//	返回 Filter() 的 error 会原样输出, 其他 error 视为开发错误输出 500.
+type TypeFunc func(paramName string, paramValue any, allowEmpty bool) (any, error)
+
+var (
+	customTypes   = map[string]TypeFunc{}
+	customTypesMu sync.RWMutex
+
+	typeNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	builtinTypes   = []string{"integer", "string", "float", "decimal", "array", "object", "file", "email", "url", "date", "datetime", "ip", "uuid"}
+)
+
+// RegisterType 注册自定义参数类型
+//
+//	注册后即可在 GetJSONBody()/GetQueries()/FilterParam() 等的 paramType 中使用, 同样支持类型修饰与数组, 比如 mobile{11}, []mobile.
+//	name 只能包含字母/数字/下划线, 不能与内置类型重名. 应在程序启动时注册, 注册错误会 panic.
+//
+//	例如:
+//		ginx.RegisterType("mobile", func(paramName string, paramValue any, allowEmpty bool) (any, error) {
+//			return ginx.Filter(paramName, paramValue, `regex(^1[3-9]\d{9}$)`, allowEmpty)
+//		})
+func RegisterType(name string, f TypeFunc) {
+	if !typeNameRegexp.MatchString(name) {
+		panic(fmt.Sprintf("ginx: 自定义参数类型名称不正确: %s", name))
+	}
+	if lo.Contains(builtinTypes, name) {
+		panic(fmt.Sprintf("ginx: 自定义参数类型不能与内置类型重名: %s", name))
+	}
+
+	customTypesMu.Lock()
+	defer customTypesMu.Unlock()
+	customTypes[name] = f
+}
+
+// customType 获取自定义参数类型
+func customType(name string) (TypeFunc, bool) {
+	customTypesMu.RLock()
+	defer customTypesMu.RUnlock()
+	f, ok := customTypes[name]
+	return f, ok
+}
+
+// Filter 校验参数类型, 不输出错误
+//
+//	同 FilterParam(), 用于在自定义类型中组合已有类型.
+func Filter(paramName string, paramValue any, paramType string, allowEmpty bool) (any, error) {
+	value, e := filterParam(paramName, paramValue, paramType, allowEmpty)
+	if e != nil {
+		return nil, e
+	}
+
+	return value, nil
+}
+
+// customTypeError 自定义类型校验错误转为参数校验错误
+func customTypeError(paramName string, err error) *paramError {
+	var e *paramError
+	if errors.As(err, &e) {
+		return e
+	}
+	if errors.Is(err, ErrParamEmpty) {
+		return emptyError(paramName)
+	}
+	if errors.Is(err, ErrParamInvalid) {
+		return invalidError(paramName)
+	}
+
+	return &paramError{httpCode: 500, code: "ParamTypeError", err: fmt.Errorf("自定义类型错误: %s: %w", paramName, err)}
+}
@@ -0,0 +1,236 @@
+// Package ginx Gin 增强函数
+//
+//	此包中出现 error 会向客户端输出 4xx/500 错误, 调用时捕获到 error 直接结束业务逻辑即可.
+package ginx
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+var (
+	modifierRegexp = regexp.MustCompile(`^-?\d*(\.\d+)?[KMG]?(,-?\d*(\.\d+)?[KMG]?)?$`) // 类型修饰 min,max, 文件大小可以带单位 K/M/G
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	regexpCache    sync.Map // 模式中的正则表达式编译缓存 map[string]*regexp.Regexp
+)
+
+// splitPattern 拆分参数模式
+//
+//	按括号外的冒号拆分为4段, 第4段允许包含冒号. 正则类型 regex(...) 与枚举中可以包含冒号.
+func splitPattern(pattern string) ([]string, bool) {
+	atoms := make([]string, 0, 4)
+	depth := 0
+	start := 0
+	for i, r := range pattern {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ':':
+			if depth == 0 && len(atoms) < 3 {
+				atoms = append(atoms, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+	atoms = append(atoms, pattern[start:])
+
+	return atoms, len(atoms) == 4
+}
+
+// typeModifier 解析类型修饰
+//
+//	格式为 <baseType><open>min,max<close>, min/max 均可省略, 仅一个数字时表示 min 与 max 相等.
+func typeModifier(paramType string, open, close byte) (baseType, min, max string, ok bool) {
+	if !strings.HasSuffix(paramType, string(close)) {
+		return "", "", "", false
+	}
+	idx := strings.LastIndexByte(paramType, open)
+	if idx <= 0 { // 枚举以 [ 开头, 不是修饰
+		return "", "", "", false
+	}
+	inner := paramType[idx+1 : len(paramType)-1]
+	if inner == "" || !modifierRegexp.MatchString(inner) {
+		return "", "", "", false
+	}
+	min, max, found := strings.Cut(inner, ",")
+	if !found {
+		max = min
+	}
+
+	return paramType[:idx], min, max, true
+}
+
+// parseBounds 解析修饰的上下限
+//
+//	支持单位 K/M/G, 按1024进制换算, 用于文件大小.
+func parseBounds(min, max string) (minVal, maxVal *float64, err error) {
+	parse := func(bound string) (*float64, error) {
+		if bound == "" {
+			return nil, nil
+		}
+		unit := 1.0
+		switch bound[len(bound)-1] {
+		case 'K':
+			unit, bound = 1<<10, bound[:len(bound)-1]
+		case 'M':
+			unit, bound = 1<<20, bound[:len(bound)-1]
+		case 'G':
+			unit, bound = 1<<30, bound[:len(bound)-1]
+		}
+		v, err := cast.ToFloat64E(bound)
+		if err != nil {
+			return nil, err
+		}
+		v *= unit
+		return &v, nil
+	}
+	if minVal, err = parse(min); err != nil {
+		return nil, nil, err
+	}
+	if maxVal, err = parse(max); err != nil {
+		return nil, nil, err
+	}
+
+	return minVal, maxVal, nil
+}
+
+// checkRange 校验数值范围
+//
+//	paramValue 为原始值, value 为类型转换后的值. 空值(空字符串会转为0)不校验, 是否允许为空由 allowEmpty 决定.
+func checkRange(paramName string, paramValue, value any, min, max string) *paramError {
+	minVal, maxVal, err := parseBounds(min, max)
+	if err != nil {
+		return typeError(paramName)
+	}
+	if valueStr, ok := paramValue.(string); paramValue == nil || ok && strings.TrimSpace(valueStr) == "" {
+		return nil
+	}
+	valueFloat, err := cast.ToFloat64E(value) // decimal 为字符串, 这里统一转为 float64 比较
+	if err != nil || reflect.TypeOf(value).Kind() == reflect.Slice {
+		return typeError(paramName)
+	}
+	if (minVal != nil && valueFloat < *minVal) || (maxVal != nil && valueFloat > *maxVal) {
+		return boundsError(paramName, "param", min, max)
+	}
+
+	return nil
+}
+
+// checkLength 校验长度
+//
+//	字符串为字符数, 数组为元素个数, 对象为字段个数, 文件为字节数. 空值不校验, 是否允许为空由 allowEmpty 决定.
+func checkLength(paramName string, value any, min, max string) *paramError {
+	minVal, maxVal, err := parseBounds(min, max)
+	if err != nil {
+		return typeError(paramName)
+	}
+	length := 0
+	keyPrefix := "param.length"
+	if file, ok := value.(*File); ok { // 文件为字节数
+		length = int(file.Size)
+		keyPrefix = "param.size"
+	} else if valueStr, ok := value.(string); ok {
+		length = utf8.RuneCountInString(valueStr)
+	} else if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map {
+		length = rv.Len()
+	} else {
+		return typeError(paramName)
+	}
+	if length == 0 {
+		return nil
+	}
+	if (minVal != nil && float64(length) < *minVal) || (maxVal != nil && float64(length) > *maxVal) {
+		return boundsError(paramName, keyPrefix, min, max)
+	}
+
+	return nil
+}
+
+// boundsError 超出上下限错误
+//
+//	keyPrefix 为消息 key 前缀, 数值范围为 param, 长度为 param.length, 文件大小为 param.size.
+func boundsError(paramName, keyPrefix, min, max string) *paramError {
+	e := &paramError{httpCode: 400, code: "ParamInvalid"}
+	switch {
+	case min != "" && min == max:
+		e.msgKey, e.args = keyPrefix+".equal", []any{paramName, min}
+	case min != "" && max != "":
+		e.msgKey, e.args = keyPrefix+".between", []any{paramName, min, max}
+	case min != "":
+		e.msgKey, e.args = keyPrefix+".min", []any{paramName, min}
+	default:
+		e.msgKey, e.args = keyPrefix+".max", []any{paramName, max}
+	}
+
+	return e
+}
+
+// compileRegexp 编译模式中的正则表达式, 编译结果会缓存
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(expr, re)
+
+	return re, nil
+}
+
+// checkFormat 校验内置格式类型, 返回格式化后的值
+//
+//	valueStr 为已去首尾空格的非空字符串.
+func checkFormat(formatType, valueStr string) (string, bool) {
+	switch formatType {
+	case "email":
+		addr, err := mail.ParseAddress(valueStr)
+		if err != nil || addr.Address != valueStr { // 不允许 "Name <email>" 格式
+			return "", false
+		}
+		return valueStr, true
+	case "url":
+		u, err := url.ParseRequestURI(valueStr)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", false
+		}
+		return valueStr, true
+	case "date":
+		t := carbon.ParseByLayout(valueStr, carbon.DateLayout)
+		if t.Error != nil {
+			return "", false
+		}
+		return t.ToDateString(), true
+	case "datetime":
+		t := carbon.ParseByLayout(valueStr, carbon.DateTimeLayout)
+		if t.Error != nil {
+			return "", false
+		}
+		return t.ToDateTimeString(), true
+	case "ip":
+		ip := net.ParseIP(valueStr)
+		if ip == nil {
+			return "", false
+		}
+		return ip.String(), true
+	case "uuid":
+		if !uuidRegexp.MatchString(valueStr) {
+			return "", false
+		}
+		return strings.ToLower(valueStr), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,151 @@
+package ginx
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		atoms   []string
+		ok      bool
+	}{
+		{"user_id:用户ID:+integer:required", []string{"user_id", "用户ID", "+integer", "required"}, true},
+		{`name:名称:string:""`, []string{"name", "名称", "string", `""`}, true},
+		{"time:时间:regex(^\\d{2}:\\d{2}$):12:00", []string{"time", "时间", "regex(^\\d{2}:\\d{2}$)", "12:00"}, true},
+		{`type:类型:["a:b","c"]:*`, []string{"type", "类型", `["a:b","c"]`, "*"}, true},
+		{"tags:标签:[]string{,10}:?", []string{"tags", "标签", "[]string{,10}", "?"}, true},
+		{"user_id:用户ID:+integer", []string{"user_id", "用户ID", "+integer"}, false},
+	}
+	for _, tt := range tests {
+		atoms, ok := splitPattern(tt.pattern)
+		if ok != tt.ok || !slices.Equal(atoms, tt.atoms) {
+			t.Errorf("splitPattern(%q) = %q, %v, want %q, %v", tt.pattern, atoms, ok, tt.atoms, tt.ok)
+		}
+	}
+}
+
+func TestTypeModifier(t *testing.T) {
+	tests := []struct {
+		paramType   string
+		open, close byte
+		baseType    string
+		min, max    string
+		ok          bool
+	}{
+		{"integer[1,100]", '[', ']', "integer", "1", "100", true},
+		{"decimal.2[0,]", '[', ']', "decimal.2", "0", "", true},
+		{"float[,-1.5]", '[', ']', "float", "", "-1.5", true},
+		{"string{2,50}", '{', '}', "string", "2", "50", true},
+		{"string{6}", '{', '}', "string", "6", "6", true},
+		{"file(image/*){,2M}", '{', '}', "file(image/*)", "", "2M", true},
+		{"[]integer{,10}", '{', '}', "[]integer", "", "10", true},
+		{`[1,2]`, '[', ']', "", "", "", false},          // 枚举
+		{`["a","b"]`, '[', ']', "", "", "", false},      // 枚举
+		{"integer[]", '[', ']', "", "", "", false},      // 空修饰
+		{"regex(^a{2}$)", '{', '}', "", "", "", false},  // 正则中的花括号
+		{"regex(^[a,b]$)", '[', ']', "", "", "", false}, // 正则中的方括号
+		{"integer[a,b]", '[', ']', "", "", "", false},   // 非数字
+		{"integer", '[', ']', "", "", "", false},        // 无修饰
+		{"string{2,50}", '[', ']', "", "", "", false},   // 括号不匹配
+		{"regex(^\\w+$){6,20}", '{', '}', "regex(^\\w+$)", "6", "20", true},
+	}
+	for _, tt := range tests {
+		baseType, min, max, ok := typeModifier(tt.paramType, tt.open, tt.close)
+		if ok != tt.ok || baseType != tt.baseType || min != tt.min || max != tt.max {
+			t.Errorf("typeModifier(%q, %q, %q) = %q, %q, %q, %v, want %q, %q, %q, %v",
+				tt.paramType, tt.open, tt.close, baseType, min, max, ok, tt.baseType, tt.min, tt.max, tt.ok)
+		}
+	}
+}
+
+func TestParseBounds(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	tests := []struct {
+		min, max       string
+		minVal, maxVal *float64
+		wantErr        bool
+	}{
+		{"1", "100", ptr(1), ptr(100), false},
+		{"", "10", nil, ptr(10), false},
+		{"0", "", ptr(0), nil, false},
+		{"", "", nil, nil, false},
+		{"-1.5", "2.5", ptr(-1.5), ptr(2.5), false},
+		{"1K", "2M", ptr(1 << 10), ptr(2 << 20), false},
+		{"", "1G", nil, ptr(1 << 30), false},
+		{"0.5K", "", ptr(512), nil, false},
+		{"a", "", nil, nil, true},
+		{"", "K", nil, nil, true},
+	}
+	equal := func(a, b *float64) bool {
+		return a == nil && b == nil || a != nil && b != nil && *a == *b
+	}
+	for _, tt := range tests {
+		minVal, maxVal, err := parseBounds(tt.min, tt.max)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBounds(%q, %q) error = %v, wantErr %v", tt.min, tt.max, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (!equal(minVal, tt.minVal) || !equal(maxVal, tt.maxVal)) {
+			t.Errorf("parseBounds(%q, %q) = %v, %v, want %v, %v", tt.min, tt.max, minVal, maxVal, tt.minVal, tt.maxVal)
+		}
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	tests := []struct {
+		paramValue, value any
+		min, max          string
+		wantErr           bool
+	}{
+		{"50", int64(50), "1", "100", false},
+		{"0", int64(0), "1", "100", true},
+		{"101", int64(101), "1", "100", true},
+		{"", int64(0), "1", "100", false}, // 空值不校验
+		{" ", int64(0), "1", "100", false},
+		{nil, int64(0), "1", "100", false},
+		{float64(0), float64(0), "1", "", true},
+		{"1.50", "1.50", "0", "1.5", false}, // decimal 为字符串
+		{"1.51", "1.51", "0", "1.5", true},
+	}
+	for _, tt := range tests {
+		e := checkRange("参数", tt.paramValue, tt.value, tt.min, tt.max)
+		if (e != nil) != tt.wantErr {
+			t.Errorf("checkRange(%#v, %#v, %q, %q) = %v, wantErr %v", tt.paramValue, tt.value, tt.min, tt.max, e, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		formatType string
+		valueStr   string
+		want       string
+		ok         bool
+	}{
+		{"email", "user@example.com", "user@example.com", true},
+		{"email", "Name <user@example.com>", "", false},
+		{"email", "user@", "", false},
+		{"url", "https://example.com/path?q=1", "https://example.com/path?q=1", true},
+		{"url", "example.com/path", "", false},
+		{"url", "/path", "", false},
+		{"date", "2024-02-29", "2024-02-29", true},
+		{"date", "2024-02-30", "", false},
+		{"date", "2024/02/01", "", false},
+		{"datetime", "2024-01-02 15:04:05", "2024-01-02 15:04:05", true},
+		{"datetime", "2024-01-02", "", false},
+		{"ip", "192.168.1.1", "192.168.1.1", true},
+		{"ip", "2001:DB8::1", "2001:db8::1", true},
+		{"ip", "256.1.1.1", "", false},
+		{"uuid", "3F2504E0-4F89-11D3-9A0C-0305E82C3301", "3f2504e0-4f89-11d3-9a0c-0305e82c3301", true},
+		{"uuid", "3f2504e0-4f89-11d3-9a0c", "", false},
+		{"mobile", "13800138000", "", false}, // 非内置格式
+	}
+	for _, tt := range tests {
+		got, ok := checkFormat(tt.formatType, tt.valueStr)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("checkFormat(%q, %q) = %q, %v, want %q, %v", tt.formatType, tt.valueStr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
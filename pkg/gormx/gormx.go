@@ -3,6 +3,8 @@ package gormx
 import (
 	"fmt"
 
+	"go-demo/pkg/metricx"
+
 	gormcache "github.com/asjdf/gorm-cache/cache"
 	gormcacheconfig "github.com/asjdf/gorm-cache/config"
 	"go.uber.org/zap"
@@ -71,6 +73,12 @@ func NewDB(req NewDBReq) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	// 指标
+	if err := db.Use(metricx.GORM(req.DBName)); err != nil {
+		zap.L().Error(err.Error())
+		return nil, err
+	}
+
 	// 连接池
 	sqlDB, err := db.DB()
 	if err != nil {
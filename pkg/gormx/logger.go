@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"go-demo/pkg/logx"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -37,7 +39,7 @@ func (l *gormZapLogger) LogMode(level logger.LogLevel) logger.Interface {
 
 func (l *gormZapLogger) Info(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Info {
-		zap.L().Info(fmt.Sprintf(msg, data...),
+		logx.L(ctx).Info(fmt.Sprintf(msg, data...),
 			zap.String("caller", utils.FileWithLineNum()),
 		)
 	}
@@ -45,7 +47,7 @@ func (l *gormZapLogger) Info(ctx context.Context, msg string, data ...interface{
 
 func (l *gormZapLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Warn {
-		zap.L().Warn(fmt.Sprintf(msg, data...),
+		logx.L(ctx).Warn(fmt.Sprintf(msg, data...),
 			zap.String("caller", utils.FileWithLineNum()),
 		)
 	}
@@ -53,7 +55,7 @@ func (l *gormZapLogger) Warn(ctx context.Context, msg string, data ...interface{
 
 func (l *gormZapLogger) Error(ctx context.Context, msg string, data ...interface{}) {
 	if l.LogLevel >= logger.Error {
-		zap.L().Error(fmt.Sprintf(msg, data...),
+		logx.L(ctx).Error(fmt.Sprintf(msg, data...),
 			zap.String("caller", utils.FileWithLineNum()),
 		)
 	}
@@ -68,12 +70,12 @@ func (l *gormZapLogger) Trace(ctx context.Context, begin time.Time, fc func() (s
 	switch {
 	case err != nil && l.LogLevel >= logger.Error && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
 		sql, rows := fc()
-		zap.L().Error("gorm", zap.Error(err), zap.String("sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
+		logx.L(ctx).Error("gorm", zap.Error(err), zap.String("sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
 	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
 		sql, rows := fc()
-		zap.L().Warn("gorm", zap.String("slow sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
+		logx.L(ctx).Warn("gorm", zap.String("slow sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
 	case l.LogLevel == logger.Info:
 		sql, rows := fc()
-		zap.L().Info("gorm", zap.String("sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
+		logx.L(ctx).Info("gorm", zap.String("sql", sql), zap.String("elapsed", fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6)), zap.Int64("rows", rows), zap.String("caller", utils.FileWithLineNum()))
 	}
 }
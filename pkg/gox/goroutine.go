@@ -2,19 +2,26 @@
 package gox
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 
-	"go.uber.org/zap"
+	"go-demo/pkg/panicx"
 )
 
 // SafeGo 安全地开启一个 Goroutine
 //
 //	这里会对 Goroutine 进行 recover 包装, 避免因为野生 Goroutine 报 panic 导致主线程崩溃退出.
+//	panic 通过 panicx 默认上报器上报, 附带 Goroutine 启动位置.
 func SafeGo(f func()) {
+	caller := ""
+	if _, file, line, ok := runtime.Caller(1); ok {
+		caller = fmt.Sprintf("%s:%d", file, line)
+	}
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				zap.L().Error(fmt.Sprint(r))
+				panicx.Capture(context.Background(), r, panicx.Event{Origin: panicx.OriginGoroutine, Caller: caller})
 			}
 		}()
 		f()
@@ -0,0 +1,166 @@
+// Package healthx 健康检查
+//
+//	/healthz 存活检查, 进程可以响应即为存活; /readyz 就绪检查, 并发检查各依赖, 全部正常且未在停止中才为就绪.
+package healthx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+)
+
+// CheckFunc 依赖检查, 返回 error 即为不可用
+type CheckFunc func(ctx context.Context) error
+
+// Checker 健康检查
+type Checker struct {
+	timeout  time.Duration // 单个依赖检查超时时间
+	names    []string
+	checks   map[string]CheckFunc
+	detail   bool        // 是否输出依赖错误详情
+	stopping atomic.Bool // 是否停止中
+
+	cacheTTL  time.Duration // 检查结果缓存时长
+	cacheMu   sync.Mutex
+	cached    map[string]checkResult // 上次检查结果
+	checkedAt time.Time              // 上次检查时间
+}
+
+// checkResult 依赖检查结果
+type checkResult struct {
+	Status    string  `json:"status"` // ok/fail
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// New 创建健康检查, timeout 为单个依赖检查超时时间
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  map[string]CheckFunc{},
+	}
+}
+
+// Add 添加依赖检查, 应在启动前添加
+func (h *Checker) Add(name string, check CheckFunc) *Checker {
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+	return h
+}
+
+// Detail 设置是否在就绪检查中输出依赖错误详情
+//
+//	错误详情可能包含地址/账号等内部信息, 默认不输出, 仅记录日志. 仅在检查接口不对外暴露时开启.
+func (h *Checker) Detail(detail bool) *Checker {
+	h.detail = detail
+	return h
+}
+
+// CacheFor 设置检查结果缓存时长, 避免频繁请求就绪检查时反复访问依赖
+func (h *Checker) CacheFor(ttl time.Duration) *Checker {
+	h.cacheTTL = ttl
+	return h
+}
+
+// Stop 标记为停止中, 之后就绪检查失败, 负载均衡不再转发新请求
+func (h *Checker) Stop() {
+	h.stopping.Store(true)
+}
+
+// Register 注册 /healthz, /readyz 到 mux
+func (h *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", h.Liveness)
+	mux.HandleFunc("/readyz", h.Readiness)
+}
+
+// Liveness 存活检查
+func (h *Checker) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+// Readiness 就绪检查
+//
+//	输出 {"status", "checks": {"<name>": {"status", "latency_ms", "error"}}}, 未就绪时状态码为 503.
+//	依赖错误记录到日志, 开启 Detail() 时才输出 error. 检查结果按 CacheFor() 缓存.
+func (h *Checker) Readiness(w http.ResponseWriter, _ *http.Request) {
+	results := h.check()
+	status, code := "ok", http.StatusOK
+	for _, result := range results {
+		if result.Status != "ok" {
+			status, code = "fail", http.StatusServiceUnavailable
+		}
+	}
+	if h.stopping.Load() {
+		status, code = "stopping", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]any{"status": status, "checks": results})
+}
+
+// check 并发检查各依赖
+//
+//	同一时间只执行一次检查, 并发请求等待并共用结果, 缓存期内直接返回上次结果. 检查不受请求取消影响.
+func (h *Checker) check() map[string]checkResult {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+	if h.cached != nil && time.Since(h.checkedAt) < h.cacheTTL {
+		return h.cached
+	}
+
+	results := make(map[string]checkResult, len(h.names))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range h.names {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+			defer cancel()
+			start := time.Now()
+			result := checkResult{Status: "ok"}
+			if err := runCheck(ctx, check); err != nil {
+				result.Status = "fail"
+				zap.L().Warn("healthx: 依赖检查失败", zap.String("check", name), zap.Error(err))
+				if h.detail {
+					result.Error = err.Error()
+				}
+			}
+			result.LatencyMS = float64(time.Since(start).Microseconds()) / 1e3
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, h.checks[name])
+	}
+	wg.Wait()
+	h.cached, h.checkedAt = results, time.Now()
+
+	return results
+}
+
+// runCheck 执行依赖检查, 检查函数不支持 context 时也在超时后返回
+func runCheck(ctx context.Context, check CheckFunc) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	data, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,182 @@
+// Package i18nx 国际化消息目录
+//
+//	消息按 locale 与 key(通常为错误码) 组织, 查找顺序: 请求 locale -> 基础语言(en-US -> en) -> 默认 locale -> key 本身.
+package i18nx
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cast"
+)
+
+// DefaultLocale 默认 locale, 未匹配到客户端语言时使用
+const DefaultLocale = "zh"
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]map[string]string{} // map[locale]map[key]message
+)
+
+// normalize 统一 locale 格式, 比如 zh_CN -> zh-cn
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Register 注册消息
+//
+//	同 locale 同 key 的消息, 后注册的覆盖先注册的.
+func Register(locale string, messages map[string]string) {
+	locale = normalize(locale)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalogs[locale]; !ok {
+		catalogs[locale] = map[string]string{}
+	}
+	for k, v := range messages {
+		catalogs[locale][k] = v
+	}
+}
+
+// RegisterIfAbsent 注册消息, 已存在的 key 不覆盖
+//
+//	用于包内置消息, 避免覆盖项目中加载的消息.
+func RegisterIfAbsent(locale string, messages map[string]string) {
+	locale = normalize(locale)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalogs[locale]; !ok {
+		catalogs[locale] = map[string]string{}
+	}
+	for k, v := range messages {
+		if _, ok := catalogs[locale][k]; !ok {
+			catalogs[locale][k] = v
+		}
+	}
+}
+
+// LoadFS 从文件系统加载消息目录
+//
+//	dir 下每个 <locale>.json 文件为一个 locale 的消息目录, 内容格式 {"key": "message"}. 出错时返回错误, 之前的文件已加载.
+func LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18nx: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("i18nx: %w", err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return fmt.Errorf("i18nx: %s: %w", entry.Name(), err)
+		}
+		Register(strings.TrimSuffix(entry.Name(), ".json"), messages)
+	}
+
+	return nil
+}
+
+// LoadDir 从目录加载消息目录, 格式见 LoadFS()
+func LoadDir(dir string) error {
+	return LoadFS(os.DirFS(dir), ".")
+}
+
+// Lookup 查找消息
+//
+//	仅查找 locale 及其基础语言, 不回退到默认 locale.
+func Lookup(locale, key string) (string, bool) {
+	locale = normalize(locale)
+	mu.RLock()
+	defer mu.RUnlock()
+	if message, ok := catalogs[locale][key]; ok {
+		return message, true
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if message, ok := catalogs[base][key]; ok {
+			return message, true
+		}
+	}
+
+	return "", false
+}
+
+// T 翻译消息
+//
+//	args 不为空时消息作为 fmt.Sprintf 格式模板. 找不到消息时依次回退到默认 locale 与 key 本身.
+func T(locale, key string, args ...any) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		message, ok = Lookup(DefaultLocale, key)
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
+
+// Supported 是否有该 locale 的消息目录
+func Supported(locale string) bool {
+	locale = normalize(locale)
+	if locale == DefaultLocale {
+		return true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Match 根据 Accept-Language 匹配 locale
+//
+//	按权重依次匹配已注册的 locale 及其基础语言, 都未匹配时返回 DefaultLocale.
+func Match(acceptLanguage string) string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+	tags := make([]tag, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		locale = normalize(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			q = cast.ToFloat64(v)
+		}
+		tags = append(tags, tag{locale: locale, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	for _, t := range tags {
+		if t.q <= 0 {
+			continue
+		}
+		if Supported(t.locale) {
+			return t.locale
+		}
+		if base, _, found := strings.Cut(t.locale, "-"); found && Supported(base) {
+			return base
+		}
+	}
+
+	return DefaultLocale
+}
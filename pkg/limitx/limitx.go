@@ -0,0 +1,199 @@
+// Package limitx 分布式限流
+//
+//	基于 Redis 的 GCRA 算法(通用信元速率算法), 多实例共享限额. Redis 不可用时回退到本地令牌桶, 此时限额按实例计算.
+package limitx
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// gcraScript GCRA 限流脚本
+//
+//	KEYS[1] 限流键; ARGV 依次为 burst/rate/period(秒)/cost.
+//	返回 {是否允许, 剩余次数, 重试等待秒数, 重置等待秒数}, 浮点数以字符串返回.
+var gcraScript = redis.NewScript(`
+redis.replicate_commands()
+
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local emission_interval = period / rate
+local increment = emission_interval * cost
+local burst_offset = emission_interval * burst
+
+local now = redis.call("TIME")
+now = (now[1] - 1483228800) + (now[2] / 1000000)
+
+local tat = redis.call("GET", key)
+if not tat then
+  tat = now
+else
+  tat = tonumber(tat)
+end
+tat = math.max(tat, now)
+
+local new_tat = tat + increment
+local allow_at = new_tat - burst_offset
+local diff = now - allow_at
+local remaining = diff / emission_interval
+
+if remaining < 0 then
+  return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+local reset_after = new_tat - now
+if reset_after > 0 then
+  redis.call("SET", key, new_tat, "EX", math.ceil(reset_after))
+end
+
+return {1, remaining, "-1", tostring(reset_after)}
+`)
+
+// Limit 限额
+type Limit struct {
+	Rate   int           // 周期内次数, <= 0 表示不限流
+	Period time.Duration // 周期
+	Burst  int           // 突发容量, 默认同 Rate
+}
+
+// PerSecond 每秒限额
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second, Burst: rate}
+}
+
+// PerMinute 每分钟限额
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute, Burst: rate}
+}
+
+// PerHour 每小时限额
+func PerHour(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Hour, Burst: rate}
+}
+
+// unlimited 是否不限流
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0 || l.Period <= 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result 限流结果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 限额, 即突发容量
+	Remaining  int           // 剩余次数
+	RetryAfter time.Duration // 被限流时多久后可以重试
+	ResetAfter time.Duration // 多久后恢复全部限额
+	Local      bool          // 是否本地令牌桶结果
+}
+
+// maxLocalBuckets 本地令牌桶最大数量, 超过后清空重建, 避免限流对象过多占用内存
+const maxLocalBuckets = 10000
+
+// Limiter 限流器
+type Limiter struct {
+	rdb *redis.Client
+
+	mu       sync.Mutex
+	buckets  map[string]*ratelimit.Bucket // 本地令牌桶, Redis 不可用时使用
+	warnedAt atomic.Int64                 // 上次记录 Redis 不可用日志的时间戳
+	failedAt atomic.Int64                 // 上次 Redis 出错的时间戳, 之后1秒内直接使用本地令牌桶
+}
+
+// NewLimiter 创建限流器
+func NewLimiter(rdb *redis.Client) *Limiter {
+	return &Limiter{
+		rdb:     rdb,
+		buckets: map[string]*ratelimit.Bucket{},
+	}
+}
+
+// Allow 消耗一次限额
+//
+//	Redis 出错时记录日志并回退到本地令牌桶, 1秒后再重试 Redis, 不返回错误. 不限流的限额直接允许.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) Result {
+	if limit.unlimited() {
+		return Result{Allowed: true, Remaining: math.MaxInt, RetryAfter: -1}
+	}
+	if l.failedAt.Load() >= time.Now().Add(-time.Second).UnixNano() {
+		return l.allowLocal(key, limit)
+	}
+
+	burst := limit.burst()
+	values, err := gcraScript.Run(ctx, l.rdb, []string{key}, burst, limit.Rate, limit.Period.Seconds(), 1).Slice()
+	if err != nil || len(values) != 4 {
+		if err != nil && ctx.Err() == nil {
+			l.failedAt.Store(time.Now().UnixNano())
+			if now := time.Now().Unix(); l.warnedAt.Swap(now) < now-10 { // 每10秒最多记录一次
+				zap.L().Warn("limitx: redis 不可用, 使用本地令牌桶", zap.Error(err))
+			}
+		}
+		return l.allowLocal(key, limit)
+	}
+
+	return Result{
+		Allowed:    cast.ToInt64(values[0]) == 1,
+		Limit:      burst,
+		Remaining:  cast.ToInt(values[1]),
+		RetryAfter: seconds(values[2]),
+		ResetAfter: seconds(values[3]),
+	}
+}
+
+// allowLocal 本地令牌桶限流
+func (l *Limiter) allowLocal(key string, limit Limit) Result {
+	burst := limit.burst()
+	interval := max(limit.Period/time.Duration(limit.Rate), time.Nanosecond) // 周期小于次数(纳秒)时间隔为0, 至少取1纳秒
+	bucketKey := key + ":" + strconv.Itoa(limit.Rate) + "/" + limit.Period.String()
+	l.mu.Lock()
+	bucket, ok := l.buckets[bucketKey]
+	if !ok {
+		if len(l.buckets) >= maxLocalBuckets {
+			l.buckets = map[string]*ratelimit.Bucket{}
+		}
+		bucket = ratelimit.NewBucketWithRate(float64(limit.Rate)/limit.Period.Seconds(), int64(burst)) // 按速率创建, 高速率时自动增大每次填充量
+		l.buckets[bucketKey] = bucket
+	}
+	l.mu.Unlock()
+
+	result := Result{Limit: burst, Local: true}
+	if bucket.TakeAvailable(1) < 1 {
+		result.RetryAfter = interval
+		result.ResetAfter = interval * time.Duration(burst)
+		return result
+	}
+	result.Allowed = true
+	result.Remaining = int(bucket.Available())
+	result.ResetAfter = interval * time.Duration(int64(burst)-bucket.Available())
+	result.RetryAfter = -1
+
+	return result
+}
+
+// seconds 秒数字符串转为时长, 负数表示无
+func seconds(value any) time.Duration {
+	f := cast.ToFloat64(value)
+	if f < 0 {
+		return -1
+	}
+	return time.Duration(f * float64(time.Second))
+}
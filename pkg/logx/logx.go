@@ -0,0 +1,37 @@
+// Package logx 上下文日志
+//
+//	请求 ID 存放在 context 中, 日志通过 L(ctx) 获取, 自动携带请求 ID, 以便关联同一请求的 API/SQL/队列日志.
+package logx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// requestIDKey 请求 ID context 键
+type requestIDKey struct{}
+
+// WithRequestID 在 context 中存放请求 ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 从 context 中获取请求 ID, 没有时为空
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// L 获取携带请求 ID 的日志
+//
+//	context 中没有请求 ID 时即为 zap.L().
+func L(ctx context.Context) *zap.Logger {
+	if requestID := RequestID(ctx); requestID != "" {
+		return zap.L().With(zap.String("request_id", requestID))
+	}
+	return zap.L()
+}
@@ -0,0 +1,103 @@
+// Package logx 上下文日志
+//
+//	请求 ID 存放在 context 中, 日志通过 L(ctx) 获取, 自动携带请求 ID, 以便关联同一请求的 API/SQL/队列日志.
+package logx
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Redacted 脱敏后的值
+const Redacted = "***"
+
+// Redactor 日志脱敏
+//
+//	字段名不区分大小写.
+type Redactor struct {
+	keys    map[string]bool
+	pattern *regexp.Regexp // 匹配 "key": value, 用于无法解析的 JSON(比如已截断)
+}
+
+// NewRedactor 创建脱敏器, keys 为需要脱敏的字段名
+func NewRedactor(keys ...string) *Redactor {
+	r := &Redactor{keys: make(map[string]bool, len(keys))}
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = true
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	if len(quoted) > 0 {
+		r.pattern = regexp.MustCompile(`(?i)"(` + strings.Join(quoted, "|") + `)"\s*:\s*("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	}
+	return r
+}
+
+// Match 字段是否需要脱敏
+func (r *Redactor) Match(key string) bool {
+	return r.keys[strings.ToLower(key)]
+}
+
+// JSON 脱敏 JSON, 任意层级的匹配字段值替换为 ***, 不是合法 JSON 时按字段名匹配替换
+func (r *Redactor) JSON(data []byte) []byte {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // 避免大整数精度丢失
+	if err := decoder.Decode(&value); err != nil {
+		if r.pattern == nil {
+			return data
+		}
+		return r.pattern.ReplaceAll(data, []byte(`"$1":"`+Redacted+`"`))
+	}
+	result, err := json.Marshal(r.value(value))
+	if err != nil {
+		return data
+	}
+	return result
+}
+
+func (r *Redactor) value(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if r.Match(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.value(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return value
+}
+
+// Values 脱敏表单/Query 参数, 返回编码后的字符串
+func (r *Redactor) Values(values url.Values) string {
+	for key := range values {
+		if r.Match(key) {
+			values[key] = []string{Redacted}
+		}
+	}
+	return strings.ReplaceAll(values.Encode(), url.QueryEscape(Redacted), Redacted)
+}
+
+// Header 脱敏请求头/响应头, 多值以逗号连接
+func (r *Redactor) Header(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		if r.Match(key) {
+			result[key] = Redacted
+		} else {
+			result[key] = strings.Join(values, ", ")
+		}
+	}
+	return result
+}
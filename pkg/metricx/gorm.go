@@ -0,0 +1,85 @@
+package metricx
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "db_query_duration_seconds",
+	Help:      "SQL 耗时",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"db", "table", "operation", "status"})
+
+const gormStartKey = "metricx:start"
+
+// gormRegistrar GORM 回调注册
+type gormRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+type gormPlugin struct {
+	name string
+}
+
+// GORM GORM 插件, 记录 SQL 耗时(按表与操作)与连接池指标, name 为数据库名称
+func GORM(name string) gorm.Plugin {
+	return &gormPlugin{name: name}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metricx:" + p.name
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, item := range []struct {
+		operation     string
+		before, after gormRegistrar
+	}{
+		{"create", callback.Create().Before("*"), callback.Create().After("*")},
+		{"query", callback.Query().Before("*"), callback.Query().After("*")},
+		{"update", callback.Update().Before("*"), callback.Update().After("*")},
+		{"delete", callback.Delete().Before("*"), callback.Delete().After("*")},
+		{"row", callback.Row().Before("*"), callback.Row().After("*")},
+		{"raw", callback.Raw().Before("*"), callback.Raw().After("*")},
+	} {
+		if err := item.before.Register("metricx:before_"+item.operation, p.before); err != nil {
+			return err
+		}
+		if err := item.after.Register("metricx:after_"+item.operation, p.after(item.operation)); err != nil {
+			return err
+		}
+	}
+
+	// 连接池
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, p.name))
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, _ := value.(time.Time)
+		status := "ok"
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			status = "error"
+		}
+		dbQueryDuration.WithLabelValues(p.name, db.Statement.Table, operation, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,96 @@
+// Package metricx Prometheus 指标
+//
+//	指标注册到默认 Registry, 包含 Go 运行时与进程指标. API 通过 Handler() 暴露, 没有 HTTP 服务的程序使用 Serve().
+package metricx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Namespace 指标名前缀
+const Namespace = "app"
+
+// HTTP 指标
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "处理中的 HTTP 请求数",
+	})
+	httpRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "被限流的 HTTP 请求数",
+	}, []string{"policy"})
+)
+
+// HTTPStart 记录 HTTP 请求开始, 返回请求结束时调用的记录函数
+//
+//	route 为路由模板, 未匹配路由时应传入固定值, 避免指标基数过大. 非标准请求方法记为 OTHER.
+func HTTPStart() func(method, route string, status int) {
+	start := time.Now()
+	httpInFlight.Inc()
+	return func(method, route string, status int) {
+		httpInFlight.Dec()
+		method = httpMethod(method)
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// httpMethod 请求方法标签, 非标准方法归为 OTHER, 避免客户端构造任意方法导致指标基数过大
+func httpMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// RateLimited 记录被限流的请求
+func RateLimited(policy string) {
+	httpRateLimited.WithLabelValues(policy).Inc()
+}
+
+// Handler 指标接口
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve 启动独立的指标服务, 用于没有 HTTP 服务的程序或仅内网访问的指标, addr 为空时不启动
+//
+//	mux 可以预先注册健康检查等接口, 为 nil 时新建.
+func Serve(addr string, mux *http.ServeMux) {
+	if addr == "" {
+		return
+	}
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mux.Handle("/metrics", Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+package metricx
+
+import (
+	"github.com/alitto/pond"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterPool 注册 Goroutine 池指标, name 为池名称
+//
+//	仅用于常驻的池, 临时创建的池注册后无法释放.
+func RegisterPool(name string, pool *pond.WorkerPool) {
+	labels := prometheus.Labels{"pool": name}
+	gauge := func(metric, help string, f func() float64) {
+		prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: Namespace, Name: metric, Help: help, ConstLabels: labels,
+		}, f))
+	}
+	counter := func(metric, help string, f func() float64) {
+		prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace, Name: metric, Help: help, ConstLabels: labels,
+		}, f))
+	}
+	gauge("pool_workers_running", "运行中的 worker 数", func() float64 { return float64(pool.RunningWorkers()) })
+	gauge("pool_workers_idle", "空闲的 worker 数", func() float64 { return float64(pool.IdleWorkers()) })
+	gauge("pool_workers_max", "最大 worker 数", func() float64 { return float64(pool.MaxWorkers()) })
+	gauge("pool_tasks_waiting", "排队中的任务数", func() float64 { return float64(pool.WaitingTasks()) })
+	counter("pool_tasks_submitted_total", "提交的任务数", func() float64 { return float64(pool.SubmittedTasks()) })
+	counter("pool_tasks_successful_total", "成功的任务数", func() float64 { return float64(pool.SuccessfulTasks()) })
+	counter("pool_tasks_failed_total", "panic 的任务数", func() float64 { return float64(pool.FailedTasks()) })
+}
@@ -0,0 +1,56 @@
+package metricx
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var redisCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Name:      "redis_command_duration_seconds",
+	Help:      "Redis 命令耗时",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+}, []string{"client", "command", "status"})
+
+type redisHook struct {
+	name string
+}
+
+// RedisHook Redis 命令耗时, name 为客户端名称, 使用 client.AddHook() 添加
+func RedisHook(name string) redis.Hook {
+	return &redisHook{name: name}
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", start, err)
+		return err
+	}
+}
+
+func (h *redisHook) observe(command string, start time.Time, err error) {
+	status := "ok"
+	if err != nil && err != redis.Nil {
+		status = "error"
+	}
+	redisCommandDuration.WithLabelValues(h.name, command, status).Observe(time.Since(start).Seconds())
+}
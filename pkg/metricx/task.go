@@ -0,0 +1,48 @@
+package metricx
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	taskProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "task_processed_total",
+		Help:      "队列任务处理数, status 为 success/failure",
+	}, []string{"task", "status"})
+	taskRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "task_retries_total",
+		Help:      "队列任务重试处理数",
+	}, []string{"task"})
+	taskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "task_duration_seconds",
+		Help:      "队列任务处理耗时",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"task"})
+)
+
+// TaskMiddleware 队列任务指标中间件, 使用 mux.Use() 添加
+func TaskMiddleware(h asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if retried, ok := asynq.GetRetryCount(ctx); ok && retried > 0 {
+			taskRetries.WithLabelValues(t.Type()).Inc()
+		}
+		start := time.Now()
+		err := h.ProcessTask(ctx, t)
+		taskDuration.WithLabelValues(t.Type()).Observe(time.Since(start).Seconds())
+		status := "success"
+		if err != nil {
+			status = "failure"
+		}
+		taskProcessed.WithLabelValues(t.Type(), status).Inc()
+
+		return err
+	})
+}
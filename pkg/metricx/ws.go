@@ -0,0 +1,36 @@
+package metricx
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	wsConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "ws_connections",
+		Help:      "WebSocket 连接数",
+	})
+	wsConnectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ws_connections_total",
+		Help:      "WebSocket 累计连接数",
+	})
+	wsMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "ws_messages_total",
+		Help:      "WebSocket 消息数, direction 为 in/out",
+	}, []string{"direction", "type"})
+)
+
+// WSConnect 记录 WebSocket 连接, 返回断开连接时调用的记录函数
+func WSConnect() func() {
+	wsConnections.Inc()
+	wsConnectionsTotal.Inc()
+	return wsConnections.Dec
+}
+
+// WSMessage 记录 WebSocket 消息, msgType 应为已知的消息类型, 避免指标基数过大
+func WSMessage(direction, msgType string) {
+	wsMessages.WithLabelValues(direction, msgType).Inc()
+}
@@ -0,0 +1,206 @@
+// Package panicx panic 上报
+//
+//	recover 后调用 Capture() 记录栈信息与上下文(请求 ID/路由/用户 id/来源), 同一 panic 在去重窗口内只上报一次, 分发到各 Sink.
+package panicx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"go-demo/pkg/logx"
+
+	"go.uber.org/zap"
+)
+
+// panic 来源
+const (
+	OriginHTTP      = "http"
+	OriginGoroutine = "goroutine"
+	OriginPool      = "pool"
+	OriginCron      = "cron"
+	OriginQueue     = "queue"
+)
+
+// Event panic 事件
+type Event struct {
+	Time       time.Time         `json:"time"`
+	Origin     string            `json:"origin"`           // 来源 http/goroutine/pool/cron/queue
+	Caller     string            `json:"caller,omitempty"` // Goroutine 启动位置
+	Value      string            `json:"value"`            // panic 值
+	Stack      string            `json:"stack"`
+	RequestID  string            `json:"request_id,omitempty"`
+	Route      string            `json:"route,omitempty"`
+	UserID     int64             `json:"user_id,omitempty"`
+	Extra      map[string]string `json:"extra,omitempty"`      // 其他信息, 比如任务类型/计划任务名
+	Suppressed int               `json:"suppressed,omitempty"` // 上次上报后去重丢弃的次数
+}
+
+// Sink 上报目标, 在 panic 所在 Goroutine 中同步调用, 耗时操作需自行异步
+type Sink func(event *Event)
+
+// Reporter panic 上报器
+type Reporter struct {
+	window time.Duration // 去重窗口
+	sinks  []Sink
+	mu     sync.Mutex
+	seen   map[string]*seenEvent // 指纹 => 上报记录
+}
+
+// seenEvent 上报记录
+type seenEvent struct {
+	reportedAt time.Time
+	suppressed int
+}
+
+// seenLimit 上报记录上限, 达到时清理过期记录, 仍达到时淘汰最早的记录
+const seenLimit = 1000
+
+// stackNoise 栈信息中每次不同的部分, 计算指纹时去除
+var stackNoise = regexp.MustCompile(`goroutine \d+|0x[0-9a-f]+`)
+
+var (
+	std   = New(time.Minute, ZapSink(nil))
+	stdMu sync.RWMutex
+)
+
+// New 创建上报器, window 为去重窗口, 0 表示不去重
+func New(window time.Duration, sinks ...Sink) *Reporter {
+	return &Reporter{
+		window: window,
+		sinks:  sinks,
+		seen:   map[string]*seenEvent{},
+	}
+}
+
+// SetDefault 设置默认上报器, 用于 Capture() 与 gox.SafeGo()
+func SetDefault(r *Reporter) {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	std = r
+}
+
+// Default 默认上报器, 未设置时输出到 zap.L()
+func Default() *Reporter {
+	stdMu.RLock()
+	defer stdMu.RUnlock()
+	return std
+}
+
+// Capture 使用默认上报器上报
+func Capture(ctx context.Context, recovered any, event Event) {
+	Default().Capture(ctx, recovered, event)
+}
+
+// Capture 上报 panic, 应在 recover 的 defer 中调用以记录 panic 时的栈信息
+//
+//	event 由调用方补充来源/路由/用户 id 等, 请求 ID 为空时从 ctx 中获取.
+func (r *Reporter) Capture(ctx context.Context, recovered any, event Event) {
+	event.Time = time.Now()
+	event.Value = fmt.Sprint(recovered)
+	if event.Stack == "" {
+		event.Stack = string(debug.Stack())
+	}
+	if event.RequestID == "" {
+		event.RequestID = logx.RequestID(ctx)
+	}
+	if !r.allow(&event) {
+		return
+	}
+
+	for _, sink := range r.sinks {
+		func() {
+			defer func() { // 上报失败不影响业务
+				if err := recover(); err != nil {
+					zap.L().Error(fmt.Sprint(err))
+				}
+			}()
+			sink(&event)
+		}()
+	}
+}
+
+// allow 去重, 同一指纹在窗口内只上报一次, 下次上报时附带丢弃次数
+func (r *Reporter) allow(event *Event) bool {
+	if r.window <= 0 {
+		return true
+	}
+	fingerprint := event.Origin + "\n" + event.Route + "\n" + event.Value + "\n" + stackNoise.ReplaceAllString(event.Stack, "")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if seen, ok := r.seen[fingerprint]; ok {
+		if event.Time.Sub(seen.reportedAt) < r.window {
+			seen.suppressed++
+			return false
+		}
+		event.Suppressed = seen.suppressed
+	}
+	if _, ok := r.seen[fingerprint]; !ok && len(r.seen) >= seenLimit {
+		oldestKey, oldestAt := "", event.Time
+		for key, seen := range r.seen {
+			if event.Time.Sub(seen.reportedAt) >= r.window {
+				delete(r.seen, key)
+			} else if !seen.reportedAt.After(oldestAt) {
+				oldestKey, oldestAt = key, seen.reportedAt
+			}
+		}
+		if len(r.seen) >= seenLimit {
+			delete(r.seen, oldestKey)
+		}
+	}
+	r.seen[fingerprint] = &seenEvent{reportedAt: event.Time}
+
+	return true
+}
+
+// fields 事件日志字段
+func (e *Event) fields() []zap.Field {
+	fields := []zap.Field{
+		zap.String("origin", e.Origin),
+		zap.String("value", e.Value),
+		zap.String("stack", e.Stack),
+	}
+	if e.Caller != "" {
+		fields = append(fields, zap.String("caller", e.Caller))
+	}
+	if e.RequestID != "" {
+		fields = append(fields, zap.String("request_id", e.RequestID))
+	}
+	if e.Route != "" {
+		fields = append(fields, zap.String("route", e.Route))
+	}
+	if e.UserID > 0 {
+		fields = append(fields, zap.Int64("user_id", e.UserID))
+	}
+	if len(e.Extra) > 0 {
+		fields = append(fields, zap.Any("extra", e.Extra))
+	}
+	if e.Suppressed > 0 {
+		fields = append(fields, zap.Int("suppressed", e.Suppressed))
+	}
+	return fields
+}
+
+// summary 事件摘要, 用于告警消息
+func (e *Event) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[panic] %s: %s", e.Origin, e.Value)
+	if e.Route != "" {
+		fmt.Fprintf(&b, "\nroute: %s", e.Route)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, "\nrequest_id: %s", e.RequestID)
+	}
+	if e.Caller != "" {
+		fmt.Fprintf(&b, "\ncaller: %s", e.Caller)
+	}
+	if e.Suppressed > 0 {
+		fmt.Fprintf(&b, "\nsuppressed: %d", e.Suppressed)
+	}
+	return b.String()
+}
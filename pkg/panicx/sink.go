@@ -0,0 +1,75 @@
+package panicx
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapSink 输出到 zap 日志, logger 为 nil 时使用 zap.L()
+func ZapSink(logger *zap.Logger) Sink {
+	noStack := zap.AddStacktrace(zapcore.FatalLevel) // 事件已包含 panic 栈信息
+	return func(event *Event) {
+		l := logger
+		if l == nil {
+			l = zap.L()
+		}
+		l.WithOptions(noStack).Error("panic", event.fields()...)
+	}
+}
+
+// FileSink 追加到本地文件, 每行一个 JSON 事件
+func FileSink(path string) Sink {
+	var mu sync.Mutex
+	var file *os.File
+	return func(event *Event) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			zap.L().Error(err.Error())
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if file == nil {
+			if file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o664); err != nil {
+				file = nil
+				zap.L().Error(err.Error())
+				return
+			}
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			zap.L().Error(err.Error())
+		}
+	}
+}
+
+// WebhookSink 异步 POST JSON 到告警 webhook
+//
+//	请求体为 {"text": 摘要, "event": 事件}, 可按告警平台格式调整.
+func WebhookSink(url string, timeout time.Duration) Sink {
+	client := &http.Client{Timeout: timeout}
+	return func(event *Event) {
+		data, err := json.Marshal(map[string]any{"text": event.summary(), "event": event})
+		if err != nil {
+			zap.L().Error(err.Error())
+			return
+		}
+		go func() {
+			resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+			if err != nil {
+				zap.L().Warn(err.Error())
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				zap.L().Warn("panic webhook: " + resp.Status)
+			}
+		}()
+	}
+}
@@ -2,119 +2,116 @@
 package queuex
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"go-demo/pkg/logx"
+
 	"github.com/goccy/go-json"
 	"github.com/hibiken/asynq"
-	"go.uber.org/zap"
 )
 
-// Enqueue 发送及时任务
-func Enqueue(client *asynq.Client, taskName string, payload map[string]any) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task); err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
+// metaKey payload 中的元数据键名, 用于携带请求 ID 等上下文信息
+const metaKey = "_meta"
 
-	return nil
+// meta 任务元数据
+type meta struct {
+	RequestID string `json:"request_id,omitempty"` // 发送任务的请求 ID
 }
 
-// LowEnqueue 发送低优先级及时任务
-func LowEnqueue(client *asynq.Client, taskName string, payload map[string]any) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		zap.L().Error(err.Error())
-		return err
+// newTask 创建任务
+//
+//	ctx 中的请求 ID 写入 payload 元数据, 不修改传入的 payload.
+func newTask(ctx context.Context, taskName string, payload map[string]any) (*asynq.Task, error) {
+	data := make(map[string]any, len(payload)+1)
+	for k, v := range payload {
+		data[k] = v
 	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task, asynq.Queue("low")); err != nil {
-		zap.L().Error(err.Error())
-		return err
+	if requestID := logx.RequestID(ctx); requestID != "" {
+		data[metaKey] = meta{RequestID: requestID}
+	}
+	payloadBytes, err := json.Marshal(data)
+	if err != nil {
+		logx.L(ctx).Error(err.Error())
+		return nil, err
 	}
 
-	return nil
+	return asynq.NewTask(taskName, payloadBytes), nil
 }
 
-// EnqueueIn 发送延时任务
-func EnqueueIn(client *asynq.Client, taskName string, payload map[string]any, delay time.Duration) error {
-	payloadBytes, err := json.Marshal(payload)
+// enqueue 发送任务
+//
+//	请求已结束或超时后仍要发送任务, 所以不继承 ctx 的取消, 仅保留其中的值.
+func enqueue(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any, opts ...asynq.Option) error {
+	task, err := newTask(ctx, taskName, payload)
 	if err != nil {
-		zap.L().Error(err.Error())
 		return err
 	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task, asynq.ProcessIn(delay)); err != nil {
-		zap.L().Error(err.Error())
+	if _, err := client.EnqueueContext(context.WithoutCancel(ctx), task, opts...); err != nil {
+		logx.L(ctx).Error(err.Error())
 		return err
 	}
 
 	return nil
 }
 
-// EnqueueAt 发送定时任务
-func EnqueueAt(client *asynq.Client, taskName string, payload map[string]any, timeAt time.Time) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task, asynq.ProcessAt(timeAt)); err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
+// Enqueue 发送及时任务
+func Enqueue(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any) error {
+	return enqueue(ctx, client, taskName, payload)
+}
 
-	return nil
+// LowEnqueue 发送低优先级及时任务
+func LowEnqueue(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any) error {
+	return enqueue(ctx, client, taskName, payload, asynq.Queue("low"))
 }
 
-// LowEnqueueIn 发送低优先级延时任务
-func LowEnqueueIn(client *asynq.Client, taskName string, payload map[string]any, delay time.Duration) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task, asynq.Queue("low"), asynq.ProcessIn(delay)); err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
+// EnqueueIn 发送延时任务
+func EnqueueIn(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any, delay time.Duration) error {
+	return enqueue(ctx, client, taskName, payload, asynq.ProcessIn(delay))
+}
 
-	return nil
+// EnqueueAt 发送定时任务
+func EnqueueAt(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any, timeAt time.Time) error {
+	return enqueue(ctx, client, taskName, payload, asynq.ProcessAt(timeAt))
 }
 
-// LowEnqueueAt 发送低优先级定时任务
-func LowEnqueueAt(client *asynq.Client, taskName string, payload map[string]any, timeAt time.Time) error {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
-	task := asynq.NewTask(taskName, payloadBytes)
-	if _, err := client.Enqueue(task, asynq.Queue("low"), asynq.ProcessAt(timeAt)); err != nil {
-		zap.L().Error(err.Error())
-		return err
-	}
+// LowEnqueueIn 发送低优先级延时任务
+func LowEnqueueIn(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any, delay time.Duration) error {
+	return enqueue(ctx, client, taskName, payload, asynq.Queue("low"), asynq.ProcessIn(delay))
+}
 
-	return nil
+// LowEnqueueAt 发送低优先级定时任务
+func LowEnqueueAt(ctx context.Context, client *asynq.Client, taskName string, payload map[string]any, timeAt time.Time) error {
+	return enqueue(ctx, client, taskName, payload, asynq.Queue("low"), asynq.ProcessAt(timeAt))
 }
 
 // Payload 从 Task 中解析 Payload
 //
-//	p 为接收结果的指针, map 指针或者 struct 指针皆可.
+//	p 为接收结果的指针, map 指针或者 struct 指针皆可, 结果中不包含元数据.
 //	解析失败返回的是 SkipRetry 的包裹, task 方法中返回这个 error 将不再重试.
 func Payload(t *asynq.Task, p any) error {
 	if err := json.Unmarshal(t.Payload(), p); err != nil {
-		zap.L().Error(err.Error())
+		logx.L(Context(context.Background(), t)).Error(err.Error())
 		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
 	}
+	if m, ok := p.(*map[string]any); ok {
+		delete(*m, metaKey)
+	}
 
 	return nil
 }
+
+// Context 由任务元数据恢复请求 ID 到 ctx
+//
+//	在 Worker 中间件中调用, 任务中使用 logx.L(ctx) 记录日志即可携带发送任务的请求 ID.
+func Context(ctx context.Context, t *asynq.Task) context.Context {
+	var payload struct {
+		Meta meta `json:"_meta"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil || payload.Meta.RequestID == "" {
+		return ctx
+	}
+
+	return logx.WithRequestID(ctx, payload.Meta.RequestID)
+}